@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"github.com/ataboo/sgp30go/sensor"
@@ -18,22 +19,22 @@ func main() {
 	}
 	defer sensor.Close()
 
-	logger.Info("Connected to sensor with serial: %d", sensor.SerialID)
+	logger.Infof("Connected to %s", sensor)
 
 	if err := sensor.SetBaseline(0x8973, 0x8aae); err != nil {
 		logger.Error("failed to set baseline", err)
 	}
 
+	measurements, errs := sensor.StartMeasuring(context.Background(), time.Second)
+	baselineTick := time.Tick(time.Second * 10)
+
 	for {
 		select {
-		case <-time.Tick(time.Second):
-			eCO2, TVOC, err := sensor.Measure()
-			if err != nil {
-				logger.Error("failed to measure", err)
-			} else {
-				logger.Infof("Measurement: eCO2 - %x, TVOC - %x", eCO2, TVOC)
-			}
-		case <-time.Tick(time.Second * 10):
+		case m := <-measurements:
+			logger.Infof("Measurement: %s", m)
+		case err := <-errs:
+			logger.Error("failed to measure", err)
+		case <-baselineTick:
 			eCo2Base, TVOCBase, err := sensor.GetBaseline()
 			if err != nil {
 				logger.Error("failed to get base", err)
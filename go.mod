@@ -1,10 +1,13 @@
 module github.com/ataboo/sgp30go
 
-go 1.12
+go 1.13
 
 require (
+	github.com/golang/protobuf v1.4.2
 	github.com/op/go-logging v0.0.0-20160315200505-970db520ece7
+	github.com/prometheus/client_golang v1.7.1
 	github.com/sigurn/crc8 v0.0.0-20160107002456-e55481d6f45c
 	github.com/sigurn/utils v0.0.0-20190728110027-e1fefb11a144 // indirect
 	golang.org/x/exp v0.0.0-20190829153037-c13cbed26979
+	google.golang.org/protobuf v1.25.0
 )
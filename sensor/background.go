@@ -0,0 +1,64 @@
+package sensor
+
+import (
+	"context"
+	"sync"
+)
+
+// background tracks the goroutines started for one connection so Close can
+// cancel them together and wait for them to exit.
+type background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     *sync.WaitGroup
+}
+
+// goBackground runs fn in a goroutine with a context cancelled by Close.
+func (s *SGP30Sensor) goBackground(fn func(ctx context.Context)) {
+	s.bgMu.Lock()
+	defer s.bgMu.Unlock()
+
+	if s.bg.ctx == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.bg = background{ctx: ctx, cancel: cancel, wg: &sync.WaitGroup{}}
+	}
+
+	ctx, wg := s.bg.ctx, s.bg.wg
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fn(ctx)
+	}()
+}
+
+// mergeContext returns a context that is done as soon as either ctx or other
+// is.
+func mergeContext(ctx, other context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-other.Done():
+			cancel()
+		case <-merged.Done():
+		}
+	}()
+
+	return merged, cancel
+}
+
+// stopBackground cancels every background goroutine and blocks until they
+// have all returned. It must not be called with the transaction lock held,
+// since the goroutines may be waiting on it.
+func (s *SGP30Sensor) stopBackground() {
+	s.bgMu.Lock()
+	bg := s.bg
+	s.bg = background{}
+	s.bgMu.Unlock()
+
+	if bg.ctx == nil {
+		return
+	}
+
+	bg.cancel()
+	bg.wg.Wait()
+}
@@ -0,0 +1,58 @@
+package sensor
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCloseStopsBackgroundGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.KeepaliveInterval = time.Millisecond
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+		MeasureAirQuality:    {412, 3},
+	})
+	mock.closeClosure = func() error { return nil }
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	streams := make([]<-chan Measurement, 3)
+	for i := range streams {
+		streams[i] = sensor.MeasureOnSignal(context.Background(), make(chan struct{}))
+	}
+
+	if runtime.NumGoroutine() < before+len(streams)+1 {
+		t.Error("expected background goroutines to be running", before, runtime.NumGoroutine())
+	}
+
+	if err := sensor.Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	for i, stream := range streams {
+		select {
+		case _, ok := <-stream:
+			if ok {
+				t.Error("unexpected measurement", i)
+			}
+		default:
+			t.Error("expected stream to be closed when Close returns", i)
+		}
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Error("expected no goroutines left after Close", before, after)
+	}
+
+	if sensor.keepaliveRunning {
+		t.Error("expected keepalive to have stopped")
+	}
+}
@@ -0,0 +1,169 @@
+package sensor
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	MaxBaselineHistory = 256
+	// BaselineMaxAge is how long a saved baseline stays valid per the
+	// datasheet.
+	BaselineMaxAge = 7 * 24 * time.Hour
+)
+
+// Baseline is a pair of baseline words. CapturedTime is when it was read
+// from the sensor and is zero for baselines built by hand.
+type Baseline struct {
+	ECO2         uint16    `json:"eco2"`
+	TVOC         uint16    `json:"tvoc"`
+	CapturedTime time.Time `json:"time"`
+}
+
+type baselineSample struct {
+	baseline Baseline
+	time     time.Time
+}
+
+func (b Baseline) ApproxEqual(other Baseline, tolerance uint16) bool {
+	return wordDiff(b.ECO2, other.ECO2) <= tolerance && wordDiff(b.TVOC, other.TVOC) <= tolerance
+}
+
+// InterpolateBaseline blends a towards b by t, clamped to [0, 1].
+func InterpolateBaseline(a, b Baseline, t float64) Baseline {
+	t = math.Max(0, math.Min(1, t))
+
+	return Baseline{
+		ECO2: lerpWord(a.ECO2, b.ECO2, t),
+		TVOC: lerpWord(a.TVOC, b.TVOC, t),
+	}
+}
+
+func wordDiff(a uint16, b uint16) uint16 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
+
+func lerpWord(a uint16, b uint16, t float64) uint16 {
+	return uint16(math.Round(float64(a) + (float64(b)-float64(a))*t))
+}
+
+func (s *SGP30Sensor) applyFallbackBaseline(cfg *Config) {
+	s.stateMu.Lock()
+	restored := s.baselineRestored
+	s.stateMu.Unlock()
+
+	fallback := cfg.FallbackBaseline
+	if fallback == nil || restored {
+		return
+	}
+
+	if err := s.SetBaseline(fallback.ECO2, fallback.TVOC); err != nil {
+		s.logError("failed to apply fallback baseline: %s", err)
+	}
+}
+
+// BaselineDrift returns how far the baselines read with GetBaseline moved over
+// the window ending now.
+func (s *SGP30Sensor) BaselineDrift(since time.Duration) (eco2Drift, tvocDrift int) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	cutoff := s.clock.Now().Add(-since)
+
+	var first *baselineSample
+	for i := range s.baselineHistory {
+		if !s.baselineHistory[i].time.Before(cutoff) {
+			first = &s.baselineHistory[i]
+			break
+		}
+	}
+
+	if first == nil {
+		return 0, 0
+	}
+
+	last := s.baselineHistory[len(s.baselineHistory)-1]
+
+	return int(last.baseline.ECO2) - int(first.baseline.ECO2), int(last.baseline.TVOC) - int(first.baseline.TVOC)
+}
+
+// BaselineStabilized reports whether the eCO2 and TVOC variance over the last
+// window baselines read with GetBaseline are both below maxVariance.
+func (s *SGP30Sensor) BaselineStabilized(window int, maxVariance float64) bool {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if window < 2 || len(s.baselineHistory) < window {
+		return false
+	}
+
+	samples := s.baselineHistory[len(s.baselineHistory)-window:]
+	eco2 := make([]float64, window)
+	tvoc := make([]float64, window)
+	for i, sample := range samples {
+		eco2[i] = float64(sample.baseline.ECO2)
+		tvoc[i] = float64(sample.baseline.TVOC)
+	}
+
+	return variance(eco2) < maxVariance && variance(tvoc) < maxVariance
+}
+
+func variance(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+
+	return sum / float64(len(values))
+}
+
+func (s *SGP30Sensor) recordBaseline(baseline Baseline) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if len(s.baselineHistory) == MaxBaselineHistory {
+		s.baselineHistory = s.baselineHistory[1:]
+	}
+
+	s.baselineHistory = append(s.baselineHistory, baselineSample{baseline: baseline, time: s.clock.Now()})
+	s.enforceBufferCap()
+}
+
+// SaveBaseline reads the current baseline and writes it to w as JSON with the
+// time it was read.
+func (s *SGP30Sensor) SaveBaseline(w io.Writer) error {
+	eCO2, TVOC, err := s.GetBaseline()
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(Baseline{ECO2: eCO2, TVOC: TVOC, CapturedTime: s.clock.Now()})
+}
+
+// LoadBaseline reads a baseline written by SaveBaseline and applies it. A
+// baseline older than BaselineMaxAge is not applied and ErrBaselineStale is
+// returned with it, so the caller can still apply it with SetBaseline.
+func (s *SGP30Sensor) LoadBaseline(r io.Reader) (Baseline, error) {
+	var saved Baseline
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return Baseline{}, err
+	}
+
+	if s.clock.Now().Sub(saved.CapturedTime) > BaselineMaxAge {
+		return saved, ErrBaselineStale
+	}
+
+	return saved, s.SetBaseline(saved.ECO2, saved.TVOC)
+}
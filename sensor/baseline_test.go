@@ -0,0 +1,274 @@
+package sensor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpolateBaseline(t *testing.T) {
+	a := Baseline{ECO2: 0x8000, TVOC: 0x9000}
+	b := Baseline{ECO2: 0x9000, TVOC: 0x8000}
+
+	table := []struct {
+		t        float64
+		expected Baseline
+	}{
+		{0, a},
+		{1, b},
+		{0.5, Baseline{ECO2: 0x8800, TVOC: 0x8800}},
+		{-0.5, a},
+		{1.5, b},
+	}
+
+	for _, row := range table {
+		blended := InterpolateBaseline(a, b, row.t)
+		if blended != row.expected {
+			t.Errorf("unexpected blend at %f, %+v, %+v", row.t, row.expected, blended)
+		}
+	}
+}
+
+func TestBaselineApproxEqual(t *testing.T) {
+	base := Baseline{ECO2: 0x8973, TVOC: 0x8aae}
+
+	table := []struct {
+		other    Baseline
+		expected bool
+	}{
+		{Baseline{ECO2: 0x8973, TVOC: 0x8aae}, true},
+		{Baseline{ECO2: 0x8973 + 10, TVOC: 0x8aae}, true},
+		{Baseline{ECO2: 0x8973, TVOC: 0x8aae - 10}, true},
+		{Baseline{ECO2: 0x8973 - 9, TVOC: 0x8aae + 9}, true},
+		{Baseline{ECO2: 0x8973 + 11, TVOC: 0x8aae}, false},
+		{Baseline{ECO2: 0x8973, TVOC: 0x8aae - 11}, false},
+	}
+
+	for _, row := range table {
+		if equal := base.ApproxEqual(row.other, 10); equal != row.expected {
+			t.Errorf("unexpected comparison with %+v, %t, %t", row.other, row.expected, equal)
+		}
+	}
+}
+
+func TestFallbackBaseline(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.FallbackBaseline = &Baseline{ECO2: 0x0102, TVOC: 0x0304}
+	sensor := NewSensor(cfg)
+	clock := _newFakeClock(sensor)
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+		MeasureAirQuality:    {400, 0},
+	})
+
+	writeClosure := mock.writeClosure
+	baselineWrites := 0
+	mock.writeClosure = func(buf []byte) error {
+		if _bytesMatchUint(buf, SetBaseline) {
+			baselineWrites++
+			if !_bytesMatch(buf, []byte{0x20, 0x1e, 0x01, 0x02, 0x17, 0x03, 0x04, 0x68}) {
+				t.Error("unexpected baseline buffer", buf)
+			}
+		}
+
+		return writeClosure(buf)
+	}
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	sensor.Measure()
+	if baselineWrites != 0 {
+		t.Error("expected no baseline write during warmup")
+	}
+
+	clock.Advance(WarmupDuration)
+	sensor.Measure()
+	sensor.Measure()
+	if baselineWrites != 1 {
+		t.Error("expected fallback baseline write after warmup", baselineWrites)
+	}
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if err := sensor.SetBaseline(0x0102, 0x0304); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	clock.Advance(WarmupDuration)
+	sensor.Measure()
+	if baselineWrites != 2 {
+		t.Error("expected only the restored baseline to be written", baselineWrites)
+	}
+}
+
+func TestBaselineDrift(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	responses := map[Command][]uint16{}
+	_mockResponses(sensor, responses)
+
+	if eco2Drift, tvocDrift := sensor.BaselineDrift(time.Hour); eco2Drift != 0 || tvocDrift != 0 {
+		t.Error("expected no drift without history", eco2Drift, tvocDrift)
+	}
+
+	for _, baseline := range [][]uint16{{0x8000, 0x9000}, {0x8010, 0x8ff0}, {0x8030, 0x8fe0}} {
+		responses[GetBaseline] = baseline
+		if _, _, err := sensor.GetBaseline(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		clock.Advance(time.Hour)
+	}
+
+	table := []struct {
+		since     time.Duration
+		eco2Drift int
+		tvocDrift int
+	}{
+		{30 * time.Minute, 0, 0},
+		{150 * time.Minute, 0x20, -0x10},
+		{4 * time.Hour, 0x30, -0x20},
+	}
+
+	for _, row := range table {
+		eco2Drift, tvocDrift := sensor.BaselineDrift(row.since)
+		if eco2Drift != row.eco2Drift || tvocDrift != row.tvocDrift {
+			t.Errorf("unexpected drift over %s, %d/%d, %d/%d", row.since, row.eco2Drift, row.tvocDrift, eco2Drift, tvocDrift)
+		}
+	}
+}
+
+func TestBaselineStabilized(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	responses := map[Command][]uint16{}
+	_mockResponses(sensor, responses)
+
+	readBaselines := func(baselines [][]uint16) {
+		for _, baseline := range baselines {
+			responses[GetBaseline] = baseline
+			if _, _, err := sensor.GetBaseline(); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			clock.Advance(time.Hour)
+		}
+	}
+
+	if sensor.BaselineStabilized(4, 100) {
+		t.Error("expected not stabilized without history")
+	}
+
+	readBaselines([][]uint16{{0x8000, 0x9000}, {0x8400, 0x8c00}, {0x7c00, 0x9200}, {0x8600, 0x8e00}})
+
+	if sensor.BaselineStabilized(4, 100) {
+		t.Error("expected not stabilized with high variance")
+	}
+
+	readBaselines([][]uint16{{0x8200, 0x8f00}, {0x8205, 0x8f02}, {0x8203, 0x8f04}, {0x8208, 0x8f01}})
+
+	if !sensor.BaselineStabilized(4, 100) {
+		t.Error("expected stabilized with low variance")
+	}
+
+	if sensor.BaselineStabilized(5, 100) {
+		t.Error("expected window reaching the noisy reads to be unstable")
+	}
+}
+
+func TestSaveLoadBaseline(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	clock := _newFakeClock(sensor)
+	_mockResponses(sensor, map[Command][]uint16{GetBaseline: {0x8973, 0x8aae}})
+
+	buffer := &bytes.Buffer{}
+	if err := sensor.SaveBaseline(buffer); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if saved := strings.TrimSpace(buffer.String()); saved != `{"eco2":35187,"tvoc":35502,"time":"2020-01-01T00:00:00Z"}` {
+		t.Error("unexpected saved baseline", saved)
+	}
+
+	saved := buffer.String()
+	clock.Advance(BaselineMaxAge - time.Hour)
+
+	restored := NewSensor(DefaultConfig())
+	restored.cfg.Delay = 0
+	restored.clock = clock
+
+	var written []byte
+	restored.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			written = buf
+			return nil
+		},
+	}
+
+	loaded, err := restored.LoadBaseline(strings.NewReader(saved))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if loaded.ECO2 != 0x8973 || loaded.TVOC != 0x8aae {
+		t.Error("unexpected loaded baseline", loaded)
+	}
+
+	if !_bytesMatch(written, CommandBytes(SetBaseline, 0x8973, 0x8aae)) {
+		t.Errorf("unexpected baseline write, %x", written)
+	}
+
+	written = nil
+	clock.Advance(2 * time.Hour)
+
+	loaded, err = restored.LoadBaseline(strings.NewReader(saved))
+	if err != ErrBaselineStale {
+		t.Error("expected stale baseline error", err)
+	}
+
+	if loaded.ECO2 != 0x8973 || loaded.TVOC != 0x8aae || !loaded.CapturedTime.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the stale baseline to be returned", loaded)
+	}
+
+	if written != nil {
+		t.Error("expected a stale baseline not to be applied", written)
+	}
+
+	if _, err := restored.LoadBaseline(strings.NewReader("not json")); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestBaselineJSON(t *testing.T) {
+	baseline := Baseline{ECO2: 0x8973, TVOC: 0x8aae, CapturedTime: time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)}
+
+	data, err := json.Marshal(baseline)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if string(data) != `{"eco2":35187,"tvoc":35502,"time":"2020-01-01T12:30:00Z"}` {
+		t.Error("unexpected json", string(data))
+	}
+
+	var decoded Baseline
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if decoded != baseline {
+		t.Error("unexpected round trip", baseline, decoded)
+	}
+}
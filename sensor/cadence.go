@@ -0,0 +1,73 @@
+package sensor
+
+import (
+	"context"
+	"time"
+)
+
+// The on-chip baseline algorithm assumes a measurement every second. Gaps
+// outside these bounds are logged and flagged on the reading.
+const (
+	CadenceMinInterval = 500 * time.Millisecond
+	CadenceMaxInterval = 1500 * time.Millisecond
+)
+
+// MeasureNow measures immediately, ignoring MinMeasureInterval. Measuring
+// faster than 1Hz slightly disturbs the sensor's dynamic baseline.
+func (s *SGP30Sensor) MeasureNow() (Measurement, error) {
+	if err := s.checkCadence(); err != nil {
+		s.logDebug("measuring now, bypassing cadence: %s", err)
+	}
+
+	return s.measure(context.Background())
+}
+
+func (s *SGP30Sensor) checkCadence() error {
+	minInterval := s.config().MinMeasureInterval
+	if minInterval <= 0 {
+		return nil
+	}
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if !s.lastMeasure.IsZero() && s.clock.Now().Sub(s.lastMeasure) < minInterval {
+		return ErrMeasureTooSoon
+	}
+
+	return nil
+}
+
+// LastMeasureInterval is the gap between the last two readings, or zero
+// before the second one.
+func (s *SGP30Sensor) LastMeasureInterval() time.Duration {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	return s.lastInterval
+}
+
+// trackMeasureTime records the reading time and reports whether the gap since
+// the previous reading was off cadence.
+func (s *SGP30Sensor) trackMeasureTime(timestamp time.Time) (offCadence bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	previous := s.lastMeasure
+	s.lastMeasure = timestamp
+
+	if previous.IsZero() {
+		return false
+	}
+
+	s.lastInterval = timestamp.Sub(previous)
+	if s.lastInterval < CadenceMinInterval || s.lastInterval > CadenceMaxInterval {
+		// The gap is left out of the message so logging doesn't allocate on
+		// every reading; LastMeasureInterval has it.
+		s.logInfo("measured off the 1s cadence the baseline algorithm expects")
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package sensor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+func TestMeasureNowBypassesCadence(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.MinMeasureInterval = time.Second
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, _, err := sensor.Measure(); err != ErrMeasureTooSoon {
+		t.Error("expected cadence error", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		m, err := sensor.MeasureNow()
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+
+		if m.ECO2 != 412 || m.TVOC != 35 {
+			t.Error("unexpected measurement", m)
+		}
+	}
+}
+
+func TestCadenceWarning(t *testing.T) {
+	backend := logging.NewMemoryBackend(8)
+	logger := logging.MustGetLogger("sgp30-cadence-test")
+	logger.SetBackend(logging.AddModuleLevel(backend))
+
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Logger = logger
+	sensor.cfg.CommandDelays = nil
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	table := []struct {
+		gap      time.Duration
+		expected bool
+	}{
+		{0, false},
+		{time.Second, false},
+		{3 * time.Second, true},
+		{200 * time.Millisecond, true},
+		{1400 * time.Millisecond, false},
+	}
+
+	warnings := 0
+	for i, row := range table {
+		clock.Advance(row.gap)
+
+		m, err := sensor.MeasureReading()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if m.OffCadence != row.expected {
+			t.Error("unexpected cadence flag", i, row.expected, m.OffCadence)
+		}
+
+		if i > 0 && sensor.LastMeasureInterval() != row.gap {
+			t.Error("unexpected interval", i, row.gap, sensor.LastMeasureInterval())
+		}
+
+		if row.expected {
+			warnings++
+		}
+	}
+
+	found := 0
+	for node := backend.Head(); node != nil; node = node.Next() {
+		if node.Record.Level == logging.INFO && strings.Contains(node.Record.Message(), "expects") {
+			found++
+		}
+	}
+
+	if found != warnings {
+		t.Error("unexpected cadence log count", warnings, found)
+	}
+}
@@ -0,0 +1,65 @@
+package sensor
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the time source for delays, timestamps and warm-up tracking.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// sleepContext sleeps d on the sensor's clock, returning early with ctx's
+// error once ctx is done. A ctx that can't be cancelled, as on the plain
+// Measure path, sleeps without a timer so reading doesn't allocate.
+func (s *SGP30Sensor) sleepContext(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if d <= 0 {
+		return nil
+	}
+
+	if ctx.Done() == nil {
+		s.clock.Sleep(d)
+		return nil
+	}
+
+	if _, ok := s.clock.(realClock); ok {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return ctx.Err()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.clock.Sleep(d)
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return ctx.Err()
+	}
+}
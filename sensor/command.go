@@ -0,0 +1,159 @@
+package sensor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/sigurn/crc8"
+)
+
+type Command uint16
+
+const (
+	// MaxReplyWords bounds the reply SendCommand will read.
+	MaxReplyWords = 64
+	// ReadUntilNAK makes SendCommand read one word at a time until the
+	// device stops acknowledging, for firmware with variable-length replies.
+	ReadUntilNAK = -1
+)
+
+var crcTable = crc8.MakeTable(CRCParams())
+
+// CRCParams returns the CRC-8 parameters from the datasheet that every word
+// on the bus is checked with.
+func CRCParams() crc8.Params {
+	return crc8.Params{
+		Poly:   Crc8Polynomial,
+		Init:   Crc8Init,
+		RefIn:  false,
+		RefOut: false,
+		XorOut: Crc8XorOut,
+		Check:  Crc8Check,
+		Name:   "CRC-8/NRSC-5",
+	}
+}
+
+// CRCCheckValue returns the driver's CRC over "123456789", which should equal
+// Crc8Check.
+func CRCCheckValue() byte {
+	return crc8.Checksum([]byte("123456789"), crcTable)
+}
+
+// CommandBytes returns the frame written to the bus for cmd, with each
+// argument word followed by its CRC.
+func CommandBytes(cmd Command, args ...uint16) []byte {
+	buffer := make([]byte, 2, 2+3*len(args))
+	binary.BigEndian.PutUint16(buffer, uint16(cmd))
+
+	for _, arg := range args {
+		buffer = append(buffer, packWordCrc(arg)...)
+	}
+
+	return buffer
+}
+
+// commandFrames holds the frames of the commands that take no arguments,
+// built once. They are arrays so every use gets its own copy, and a
+// Connection that modifies the buffer it is given can't corrupt them.
+var commandFrames = map[Command][2]byte{}
+
+func init() {
+	for _, cmd := range []Command{InitAirQuality, MeasureAirQuality, GetBaseline, MeasureTest, GetFeatureSetVersion, MeasureRawSignals, GetSerialID} {
+		var frame [2]byte
+		copy(frame[:], CommandBytes(cmd))
+		commandFrames[cmd] = frame
+	}
+}
+
+// fixedFrame returns the precomputed frame for cmd, building one for commands
+// outside the table.
+func fixedFrame(cmd Command) [2]byte {
+	if frame, ok := commandFrames[cmd]; ok {
+		return frame
+	}
+
+	var frame [2]byte
+	binary.BigEndian.PutUint16(frame[:], uint16(cmd))
+
+	return frame
+}
+
+// commandFrame returns a fresh copy of the frame for cmd.
+func commandFrame(cmd Command) []byte {
+	frame := fixedFrame(cmd)
+	return frame[:]
+}
+
+func packWordCrc(word uint16) []byte {
+	buffer := make([]byte, 2, 3)
+	binary.BigEndian.PutUint16(buffer, word)
+
+	return append(buffer, ComputeCRC(buffer))
+}
+
+// ComputeCRC returns the SGP30 CRC-8 of data, normally one 2 byte word.
+func ComputeCRC(data []byte) byte {
+	return crc8.Checksum(data, crcTable)
+}
+
+// VerifyWordCRC reports whether word, 2 data bytes followed by their CRC as
+// sent on the bus, is intact.
+func VerifyWordCRC(word []byte) bool {
+	return len(word) == 3 && ComputeCRC(word[:2]) == word[2]
+}
+
+// SendCommand writes cmd with args and reads replyWords words back, or reads
+// until the device NAKs when replyWords is ReadUntilNAK.
+func (s *SGP30Sensor) SendCommand(cmd Command, replyWords int, args ...uint16) ([]uint16, error) {
+	if replyWords > MaxReplyWords || replyWords < ReadUntilNAK {
+		return nil, fmt.Errorf("invalid reply size %d, max %d", replyWords, MaxReplyWords)
+	}
+
+	frame := CommandBytes(cmd, args...)
+	if replyWords != ReadUntilNAK {
+		return s.readWordsContext(context.Background(), frame, replyWords)
+	}
+
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	return s.readUntilNAK(context.Background(), frame)
+}
+
+func (s *SGP30Sensor) readUntilNAK(ctx context.Context, command []byte) ([]uint16, error) {
+	if s.i2cConnection == nil {
+		return nil, fmt.Errorf("i2c not connected")
+	}
+
+	if err := s.i2cConnection.Write(command); err != nil {
+		return nil, mapBusError(err)
+	}
+
+	if err := s.sleepContext(ctx, s.commandDelay(command, 0, s.delay())); err != nil {
+		return nil, err
+	}
+
+	var words []uint16
+	buf := make([]byte, 3)
+	for len(words) < MaxReplyWords {
+		clearBytes(buf)
+		err := s.i2cConnection.Read(buf)
+		if isNAK(err) {
+			break
+		}
+
+		if err != nil {
+			return nil, mapBusError(err)
+		}
+
+		if crc := ComputeCRC(buf[:2]); crc != buf[2] {
+			s.crcErrors++
+			return nil, &CRCError{Expected: crc, Got: buf[2], WordIndex: len(words)}
+		}
+
+		words = append(words, binary.BigEndian.Uint16(buf))
+	}
+
+	return words, nil
+}
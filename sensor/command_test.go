@@ -0,0 +1,207 @@
+package sensor
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCommandBytes(t *testing.T) {
+	table := []struct {
+		cmd      Command
+		args     []uint16
+		expected []byte
+	}{
+		{MeasureAirQuality, nil, []byte{0x20, 0x08}},
+		{SetBaseline, []uint16{0x0102, 0x0304}, []byte{0x20, 0x1e, 0x01, 0x02, 0x17, 0x03, 0x04, 0x68}},
+		{SetHumidity, []uint16{0x0f80}, []byte{0x20, 0x61, 0x0f, 0x80, 0x62}},
+	}
+
+	for _, row := range table {
+		if frame := CommandBytes(row.cmd, row.args...); !_bytesMatch(frame, row.expected) {
+			t.Errorf("unexpected frame for %x, %x, %x", row.cmd, row.expected, frame)
+		}
+	}
+}
+
+func TestSendCommandFixedReply(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_mockResponses(sensor, map[Command][]uint16{0x3701: {0x0102, 0x0304}})
+
+	words, err := sensor.SendCommand(0x3701, 2)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(words) != 2 || words[0] != 0x0102 || words[1] != 0x0304 {
+		t.Error("unexpected reply", words)
+	}
+
+	if _, err := sensor.SendCommand(0x3701, MaxReplyWords+1); err == nil {
+		t.Error("expected error for oversized reply")
+	}
+}
+
+func TestSendCommandReadUntilNAK(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	mock := &_mockI2cConnection{}
+	sensor.i2cConnection = mock
+
+	var written []byte
+	mock.writeClosure = func(buf []byte) error {
+		written = buf
+		return nil
+	}
+
+	stream := []uint16{0x1111, 0x2222, 0x3333, 0x4444, 0x5555}
+	reads := 0
+	mock.readClosure = func(buf []byte) error {
+		if reads == len(stream) {
+			return &os.PathError{Op: "read", Path: DefaultI2CFsPath, Err: errNAK}
+		}
+
+		copy(buf, packWordCrc(stream[reads]))
+		reads++
+
+		return nil
+	}
+
+	words, err := sensor.SendCommand(0x3702, ReadUntilNAK, 0x00ff)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !_bytesMatch(written, CommandBytes(0x3702, 0x00ff)) {
+		t.Error("unexpected command frame", written)
+	}
+
+	if len(words) != len(stream) {
+		t.Fatal("unexpected word count", len(words))
+	}
+
+	for i := range stream {
+		if words[i] != stream[i] {
+			t.Error("unexpected word", i, words[i])
+		}
+	}
+}
+
+func TestSendCommandReadUntilNAKCapped(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error { return nil },
+		readClosure: func(buf []byte) error {
+			copy(buf, packWordCrc(0xabcd))
+			return nil
+		},
+	}
+
+	words, err := sensor.SendCommand(0x3702, ReadUntilNAK)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(words) != MaxReplyWords {
+		t.Error("expected reply capped at MaxReplyWords", len(words))
+	}
+}
+
+func TestCRCParams(t *testing.T) {
+	params := CRCParams()
+	if params.Poly != 0x31 || params.Init != 0xFF || params.RefIn || params.RefOut || params.XorOut != 0x00 {
+		t.Errorf("unexpected crc params, %+v", params)
+	}
+
+	if params.Check != Crc8Check {
+		t.Error("unexpected check in params", params.Check)
+	}
+
+	if check := CRCCheckValue(); check != Crc8Check {
+		t.Errorf("unexpected check value, %x, %x", Crc8Check, check)
+	}
+}
+
+func TestComputeCRC(t *testing.T) {
+	table := []struct {
+		data     []byte
+		expected byte
+	}{
+		{[]byte{0xBE, 0xEF}, 0x92},
+		{[]byte{0x01, 0x02}, 0x17},
+		{[]byte{0x03, 0x04}, 0x68},
+		{[]byte("123456789"), Crc8Check},
+	}
+
+	for _, row := range table {
+		if crc := ComputeCRC(row.data); crc != row.expected {
+			t.Errorf("crc mismatch for %x, %x, %x", row.data, row.expected, crc)
+		}
+	}
+}
+
+func TestVerifyWordCRC(t *testing.T) {
+	table := []struct {
+		word     []byte
+		expected bool
+	}{
+		{[]byte{0xBE, 0xEF, 0x92}, true},
+		{[]byte{0x01, 0x02, 0x17}, true},
+		{[]byte{0xBE, 0xEF, 0x93}, false},
+		{[]byte{0xBE, 0xEE, 0x92}, false},
+		{[]byte{0xBE, 0xEF}, false},
+		{[]byte{0xBE, 0xEF, 0x92, 0x00}, false},
+	}
+
+	for _, row := range table {
+		if valid := VerifyWordCRC(row.word); valid != row.expected {
+			t.Errorf("unexpected verification for %x, %t, %t", row.word, row.expected, valid)
+		}
+	}
+}
+
+func TestCommandFramesSurviveModifyingConnection(t *testing.T) {
+	var written [][]byte
+	newSensor := func() *SGP30Sensor {
+		sensor := NewSensor(DefaultConfig())
+		sensor.cfg.Delay = 0
+		sensor.cfg.CommandDelays = nil
+		sensor.i2cConnection = &_mockI2cConnection{
+			writeClosure: func(buf []byte) error {
+				written = append(written, append([]byte(nil), buf...))
+				for i := range buf {
+					buf[i] = 0xff
+				}
+
+				return nil
+			},
+			readClosure: func(buf []byte) error {
+				copy(buf, []byte{0x01, 0x9c, 0x31, 0x00, 0x23, 0x54})
+				return nil
+			},
+		}
+
+		return sensor
+	}
+
+	for _, sensor := range []*SGP30Sensor{newSensor(), newSensor()} {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if _, err := sensor.readWordsUint(MeasureAirQuality, 2); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	for _, buf := range written {
+		if !_bytesMatchUint(buf, MeasureAirQuality) {
+			t.Errorf("expected every write to carry the measure frame, %x", buf)
+		}
+	}
+
+	if frame := commandFrame(MeasureAirQuality); !_bytesMatchUint(frame, MeasureAirQuality) {
+		t.Errorf("expected the shared frame to be intact, %x", frame)
+	}
+}
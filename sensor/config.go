@@ -0,0 +1,146 @@
+package sensor
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// UpdateConfig applies fn to a copy of the config and swaps it in while
+// holding the transaction lock, keeping the previous config if the result is
+// invalid. Operations already running finish with the config they started
+// with.
+func (s *SGP30Sensor) UpdateConfig(fn func(*Config)) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	updated := s.config().clone()
+	fn(updated)
+	if updated.Logger == nil {
+		updated.Logger = NopLogger{}
+	}
+
+	if err := updated.validate(); err != nil {
+		s.logError("rejected config update: %s", err)
+		return err
+	}
+
+	s.cfgMu.Lock()
+	s.cfg = updated
+	s.cfgMu.Unlock()
+
+	return nil
+}
+
+// clone copies c along with the map and pointer fields, so changing the copy
+// never reaches the original.
+func (c *Config) clone() *Config {
+	copied := *c
+
+	if c.CommandDelays != nil {
+		copied.CommandDelays = make(map[Command]time.Duration, len(c.CommandDelays))
+		for cmd, delay := range c.CommandDelays {
+			copied.CommandDelays[cmd] = delay
+		}
+	}
+
+	if c.FallbackBaseline != nil {
+		baseline := *c.FallbackBaseline
+		copied.FallbackBaseline = &baseline
+	}
+
+	return &copied
+}
+
+// config returns the current config, which must not be modified.
+func (s *SGP30Sensor) config() *Config {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+
+	return s.cfg
+}
+
+func (c *Config) validate() error {
+	if c.I2CFsPath == "" {
+		return fmt.Errorf("i2c FS path must be set")
+	}
+
+	if c.Delay < 0 || c.DelayMillis < 0 {
+		return fmt.Errorf("delay must not be negative")
+	}
+
+	for cmd, delay := range c.CommandDelays {
+		if delay < 0 {
+			return fmt.Errorf("delay for command %x must not be negative", uint16(cmd))
+		}
+	}
+
+	if c.MeasureInterval <= 0 {
+		return fmt.Errorf("measure interval must be positive")
+	}
+
+	return nil
+}
+
+// ConfigSnapshot returns a copy of the effective config for diagnostics. The
+// logger is left out, hooks and writers are reported as whether they are set,
+// durations are rendered as strings and maps are copied with string keys.
+func (s *SGP30Sensor) ConfigSnapshot() map[string]interface{} {
+	snapshot := make(map[string]interface{})
+	cfg := s.config()
+	value := reflect.ValueOf(*cfg)
+
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Name
+		field := value.Field(i)
+
+		switch {
+		case name == "Logger":
+			continue
+		case name == "I2CAddr":
+			snapshot[name] = fmt.Sprintf("0x%02x", cfg.I2CAddr)
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			snapshot[name] = time.Duration(field.Int()).String()
+		case field.Kind() == reflect.Func || field.Kind() == reflect.Interface:
+			snapshot[name] = !field.IsNil()
+		case field.Kind() == reflect.Map:
+			if field.IsNil() {
+				snapshot[name] = nil
+			} else {
+				snapshot[name] = snapshotMap(field)
+			}
+		case field.Kind() == reflect.Ptr:
+			if field.IsNil() {
+				snapshot[name] = nil
+			} else {
+				snapshot[name] = field.Elem().Interface()
+			}
+		default:
+			snapshot[name] = field.Interface()
+		}
+	}
+
+	return snapshot
+}
+
+// snapshotMap copies m with Command keys in hex and durations as strings.
+func snapshotMap(m reflect.Value) map[string]interface{} {
+	copied := make(map[string]interface{}, m.Len())
+
+	iter := m.MapRange()
+	for iter.Next() {
+		key := fmt.Sprint(iter.Key().Interface())
+		if cmd, ok := iter.Key().Interface().(Command); ok {
+			key = fmt.Sprintf("0x%04x", uint16(cmd))
+		}
+
+		value := iter.Value().Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+
+		copied[key] = value
+	}
+
+	return copied
+}
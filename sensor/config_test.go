@@ -0,0 +1,168 @@
+package sensor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateConfig(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.CommandDelays = nil
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{400, 0}})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err := sensor.UpdateConfig(func(cfg *Config) {
+		cfg.Delay = 25 * time.Millisecond
+	})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != 10*time.Millisecond || clock.sleeps[1] != 25*time.Millisecond {
+		t.Error("unexpected delays", clock.sleeps)
+	}
+
+	err = sensor.UpdateConfig(func(cfg *Config) {
+		cfg.Delay = 50 * time.Millisecond
+		cfg.I2CFsPath = ""
+	})
+	if err == nil {
+		t.Error("expected validation error")
+	}
+
+	if sensor.cfg.Delay != 25*time.Millisecond || sensor.cfg.I2CFsPath != DefaultI2CFsPath {
+		t.Error("expected config to be rolled back", sensor.cfg)
+	}
+}
+
+func TestUpdateConfigRollsBackMaps(t *testing.T) {
+	cfg := DefaultConfig()
+	sensor := NewSensor(cfg)
+
+	cfg.CommandDelays[MeasureAirQuality] = time.Hour
+	if sensor.cfg.CommandDelays[MeasureAirQuality] != 12*time.Millisecond {
+		t.Error("expected NewSensor to copy the command delays", sensor.cfg.CommandDelays)
+	}
+
+	err := sensor.UpdateConfig(func(cfg *Config) {
+		cfg.CommandDelays[MeasureAirQuality] = -time.Second
+	})
+	if err == nil {
+		t.Error("expected validation error")
+	}
+
+	if delays := sensor.ConfigSnapshot()["CommandDelays"].(map[string]interface{}); delays["0x2008"] != "12ms" {
+		t.Error("expected the rejected delay to be rolled back", delays)
+	}
+}
+
+func TestUpdateConfigConcurrentMeasure(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CommandDelays = nil
+	cfg.SmoothingWindow = 2
+	sensor := NewSensor(cfg)
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}, {420, 40}})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			sensor.MeasureNow()
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		err := sensor.UpdateConfig(func(cfg *Config) {
+			cfg.Delay = time.Duration(i%3) * time.Millisecond
+			cfg.QuantizeECO2 = uint16(i % 5)
+		})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	<-done
+
+	if cfg.Delay != DefaultDelay || cfg.QuantizeECO2 != 0 {
+		t.Error("expected the caller's config to be left alone", cfg)
+	}
+}
+
+func TestLegacyDelayMillis(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CommandDelays = nil
+	cfg.DelayMillis = 25
+	sensor := NewSensor(cfg)
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{400, 0}})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	sensor.cfg.DelayMillis = 0
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := []time.Duration{25 * time.Millisecond, DefaultDelay}
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != expected[0] || clock.sleeps[1] != expected[1] {
+		t.Error("expected the legacy DelayMillis to override the default Delay", clock.sleeps)
+	}
+}
+
+func TestConfigSnapshot(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.I2CFsPath = "/dev/i2c-3"
+	cfg.I2CAddr = 0x59
+	cfg.Delay = 25 * time.Millisecond
+	cfg.Retries = 2
+	cfg.FallbackBaseline = &Baseline{ECO2: 0x8973, TVOC: 0x8aae}
+	cfg.PostProcess = func(m Measurement) Measurement {
+		return m
+	}
+	sensor := NewSensor(cfg)
+
+	snapshot := sensor.ConfigSnapshot()
+
+	expected := map[string]interface{}{
+		"I2CFsPath":        "/dev/i2c-3",
+		"I2CAddr":          "0x59",
+		"Delay":            "25ms",
+		"Retries":          2,
+		"MeasureInterval":  "1s",
+		"FallbackBaseline": Baseline{ECO2: 0x8973, TVOC: 0x8aae},
+		"PostProcess":      true,
+		"Metrics":          false,
+	}
+
+	for key, value := range expected {
+		if snapshot[key] != value {
+			t.Errorf("unexpected %s, %v, %v", key, value, snapshot[key])
+		}
+	}
+
+	if _, ok := snapshot["Logger"]; ok {
+		t.Error("expected logger to be omitted")
+	}
+
+	delays, ok := snapshot["CommandDelays"].(map[string]interface{})
+	if !ok || delays["0x2008"] != "12ms" || delays["0x2032"] != "220ms" {
+		t.Error("unexpected command delays", snapshot["CommandDelays"])
+	}
+
+	delays["0x2008"] = "1h"
+	delays["0x2003"] = "1h"
+	if sensor.cfg.CommandDelays[MeasureAirQuality] != 12*time.Millisecond || len(sensor.cfg.CommandDelays) != len(DefaultCommandDelays()) {
+		t.Error("expected the snapshot not to share the config's map", sensor.cfg.CommandDelays)
+	}
+}
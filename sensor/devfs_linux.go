@@ -0,0 +1,55 @@
+//go:build linux
+// +build linux
+
+package sensor
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// i2cRDWR is the i2c-dev ioctl for combined transfers. Unlike I2C_SLAVE, which
+// x/exp/io/i2c uses to bind an address, it accepts the reserved address 0x00.
+const i2cRDWR = 0x0707
+
+// i2cMsg and i2cRDWRData mirror struct i2c_msg and struct
+// i2c_rdwr_ioctl_data from linux/i2c-dev.h.
+type i2cMsg struct {
+	addr  uint16
+	flags uint16
+	len   uint16
+	buf   *byte
+}
+
+type i2cRDWRData struct {
+	msgs  *i2cMsg
+	nmsgs uint32
+}
+
+// GeneralCall writes data to address 0x00 with one I2C_RDWR message, since
+// i2c-dev refuses to open a connection to the general call address.
+func (devfsOpener) GeneralCall(path string, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	msg := i2cMsg{addr: uint16(GeneralCallAddr), len: uint16(len(data)), buf: &data[0]}
+	rdwr := i2cRDWRData{msgs: &msg, nmsgs: 1}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), i2cRDWR, uintptr(unsafe.Pointer(&rdwr)))
+	runtime.KeepAlive(&msg)
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return &os.PathError{Op: "ioctl", Path: path, Err: errno}
+	}
+
+	return nil
+}
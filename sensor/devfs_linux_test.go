@@ -0,0 +1,44 @@
+//go:build linux
+// +build linux
+
+package sensor
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestDevfsSoftReset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sgp30")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := DefaultConfig()
+	cfg.I2CFsPath = filepath.Join(dir, "i2c-1")
+	sensor := NewSensor(cfg)
+	_newFakeClock(sensor)
+
+	if _, ok := sensor.opener.(GeneralCaller); !ok {
+		t.Fatal("expected the devfs opener to send general calls")
+	}
+
+	if err := sensor.SoftReset(); !os.IsNotExist(err) {
+		t.Error("expected a missing bus error", err)
+	}
+
+	// A regular file has no i2c-dev ioctls, so getting ENOTTY back shows the
+	// I2C_RDWR ioctl was issued on the bus itself.
+	if err := ioutil.WriteFile(cfg.I2CFsPath, nil, 0600); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if err := sensor.SoftReset(); !errors.Is(err, syscall.ENOTTY) {
+		t.Error("expected the ioctl to reach the bus", err)
+	}
+}
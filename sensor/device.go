@@ -0,0 +1,73 @@
+package sensor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/exp/io/i2c"
+)
+
+const DevicePollInterval = 100 * time.Millisecond
+
+// Opener opens connections to I2CFsPath, for Init, Reconnect and SoftReset.
+// The default uses i2c-dev.
+type Opener interface {
+	Stat(name string) (os.FileInfo, error)
+	Open(path string, addr byte) (Connection, error)
+}
+
+// GeneralCaller is an optional Opener capability SoftReset uses to write data
+// to the general call address 0x00 on the bus at path. Openers without it get
+// a connection from Open(path, GeneralCallAddr) instead.
+type GeneralCaller interface {
+	GeneralCall(path string, data []byte) error
+}
+
+type devfsOpener struct{}
+
+func (devfsOpener) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (devfsOpener) Open(path string, addr byte) (Connection, error) {
+	device, err := i2c.Open(&i2c.Devfs{Dev: path}, int(addr))
+	if err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// logBusSpeed notes that Frequency is not applied, since i2c-dev can't change
+// the bus clock.
+func (s *SGP30Sensor) logBusSpeed() {
+	if frequency := s.config().Frequency; frequency != DefaultFrequency {
+		s.logInfo("Frequency %.0f Hz is not applied, the bus speed is set by the i2c adapter driver", frequency)
+		return
+	}
+
+	s.logDebug("bus speed is set by the i2c adapter driver")
+}
+
+// waitForDevice polls for I2CFsPath until WaitForDevice elapses or ctx is
+// done.
+func (s *SGP30Sensor) waitForDevice(ctx context.Context) error {
+	cfg := s.config()
+	deadline := s.clock.Now().Add(cfg.WaitForDevice)
+
+	for {
+		if _, err := s.opener.Stat(cfg.I2CFsPath); err == nil {
+			return nil
+		}
+
+		if !s.clock.Now().Before(deadline) {
+			return fmt.Errorf("%w: %s", ErrDeviceNotFound, cfg.I2CFsPath)
+		}
+
+		if err := s.sleepContext(ctx, DevicePollInterval); err != nil {
+			return err
+		}
+	}
+}
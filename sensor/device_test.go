@@ -0,0 +1,202 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+type _mockOpener struct {
+	statClosure func(name string) (os.FileInfo, error)
+	openClosure func(path string, addr byte) (Connection, error)
+}
+
+func (m *_mockOpener) Stat(name string) (os.FileInfo, error) {
+	return m.statClosure(name)
+}
+
+func (m *_mockOpener) Open(path string, addr byte) (Connection, error) {
+	return m.openClosure(path, addr)
+}
+
+func TestWaitForDeviceAppears(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.WaitForDevice = time.Second
+	clock := _newFakeClock(sensor)
+	appearAt := clock.Now().Add(300 * time.Millisecond)
+
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			if name != DefaultI2CFsPath {
+				t.Error("unexpected path", name)
+			}
+
+			if clock.Now().Before(appearAt) {
+				return nil, os.ErrNotExist
+			}
+
+			return nil, nil
+		},
+	}
+
+	if err := sensor.waitForDevice(context.Background()); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if len(clock.sleeps) != 3 {
+		t.Error("unexpected poll count", 3, len(clock.sleeps))
+	}
+}
+
+func TestWaitForDeviceTimesOut(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.WaitForDevice = time.Second
+	clock := _newFakeClock(sensor)
+	start := clock.Now()
+
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, os.ErrNotExist
+		},
+	}
+
+	if err := sensor.Init(); !errors.Is(err, ErrDeviceNotFound) {
+		t.Error("expected device not found", err)
+	}
+
+	if elapsed := clock.Now().Sub(start); elapsed != time.Second {
+		t.Error("expected init to wait out the timeout", elapsed)
+	}
+}
+
+func TestWaitForDeviceCancelled(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.WaitForDevice = time.Minute
+	clock := _newFakeClock(sensor)
+	start := clock.Now()
+
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, os.ErrNotExist
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock.onSleep = func(d time.Duration) {
+		if len(clock.sleeps) == 2 {
+			cancel()
+		}
+	}
+
+	if err := sensor.InitContext(ctx); err != context.Canceled {
+		t.Error("expected context error", err)
+	}
+
+	if elapsed := clock.Now().Sub(start); elapsed >= time.Minute {
+		t.Error("expected cancel to stop polling", elapsed)
+	}
+}
+
+func TestStartI2CConnectionOpenFails(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		openClosure: func(path string, addr byte) (Connection, error) {
+			return nil, fmt.Errorf("permission denied")
+		},
+	}
+
+	if err := sensor.startI2CConnection(context.Background()); err == nil || err.Error() != "permission denied" {
+		t.Error("expected open error", err)
+	}
+
+	if sensor.i2cConnection != nil {
+		t.Error("expected no connection after a failed open")
+	}
+}
+
+func TestStartI2CConnectionOpens(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+	sensor.i2cConnection = nil
+
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		openClosure: func(path string, addr byte) (Connection, error) {
+			if path != DefaultI2CFsPath || addr != DefaultI2CAddr {
+				t.Error("unexpected open args", path, addr)
+			}
+
+			return mock, nil
+		},
+	}
+
+	if err := sensor.Init(); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if sensor.i2cConnection != mock || sensor.SerialID != 0x010203040506 {
+		t.Error("expected init through the opened connection")
+	}
+}
+
+func TestFrequencyIsInert(t *testing.T) {
+	backend := logging.NewMemoryBackend(8)
+	logger := logging.MustGetLogger("sgp30-frequency-test")
+	logger.SetBackend(logging.AddModuleLevel(backend))
+
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Logger = logger
+	sensor.cfg.Frequency = 400000
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+	sensor.i2cConnection = nil
+
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		openClosure: func(path string, addr byte) (Connection, error) {
+			if path != DefaultI2CFsPath || addr != DefaultI2CAddr {
+				t.Error("expected Frequency not to change the open", path, addr)
+			}
+
+			return mock, nil
+		},
+	}
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	warned := false
+	for node := backend.Head(); node != nil; node = node.Next() {
+		if node.Record.Level == logging.INFO && strings.Contains(node.Record.Message(), "400000 Hz is not applied") {
+			warned = true
+		}
+	}
+
+	if !warned {
+		t.Error("expected a warning that Frequency is not applied")
+	}
+}
@@ -0,0 +1,62 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// The binary record is big-endian:
+//
+//	version(1) serial(6) eCO2(2) TVOC(2) unix seconds(4)
+const (
+	MeasurementBinaryVersion byte = 1
+	MeasurementBinarySize         = 15
+)
+
+func (m Measurement) MarshalBinary() ([]byte, error) {
+	if m.SerialID>>48 != 0 {
+		return nil, fmt.Errorf("serial %x exceeds 48 bits", m.SerialID)
+	}
+
+	unix := m.Timestamp.Unix()
+	if unix < 0 || unix > math.MaxUint32 {
+		return nil, fmt.Errorf("timestamp %s out of range", m.Timestamp)
+	}
+
+	data := make([]byte, MeasurementBinarySize)
+	data[0] = MeasurementBinaryVersion
+
+	var serial [8]byte
+	binary.BigEndian.PutUint64(serial[:], m.SerialID)
+	copy(data[1:7], serial[2:])
+
+	binary.BigEndian.PutUint16(data[7:9], m.ECO2)
+	binary.BigEndian.PutUint16(data[9:11], m.TVOC)
+	binary.BigEndian.PutUint32(data[11:15], uint32(unix))
+
+	return data, nil
+}
+
+func (m *Measurement) UnmarshalBinary(data []byte) error {
+	if len(data) != MeasurementBinarySize {
+		return fmt.Errorf("expected %d bytes, got %d", MeasurementBinarySize, len(data))
+	}
+
+	if data[0] != MeasurementBinaryVersion {
+		return fmt.Errorf("unsupported measurement version %d", data[0])
+	}
+
+	var serial [8]byte
+	copy(serial[2:], data[1:7])
+
+	*m = Measurement{
+		SerialID:  binary.BigEndian.Uint64(serial[:]),
+		ECO2:      binary.BigEndian.Uint16(data[7:9]),
+		TVOC:      binary.BigEndian.Uint16(data[9:11]),
+		Timestamp: time.Unix(int64(binary.BigEndian.Uint32(data[11:15])), 0).UTC(),
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package sensor
+
+import (
+	"encoding"
+	"testing"
+	"time"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = Measurement{}
+	_ encoding.BinaryUnmarshaler = &Measurement{}
+)
+
+func TestMeasurementBinaryRoundTrip(t *testing.T) {
+	table := []Measurement{
+		{},
+		{ECO2: 412, TVOC: 35, Timestamp: time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC), SerialID: 0x010203040506},
+		{ECO2: 60000, TVOC: 60000, Timestamp: time.Date(2106, 2, 7, 6, 28, 15, 0, time.UTC), SerialID: 0xffffffffffff},
+	}
+
+	for _, m := range table {
+		if m.Timestamp.IsZero() {
+			m.Timestamp = time.Unix(0, 0).UTC()
+		}
+
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if len(data) != MeasurementBinarySize {
+			t.Error("unexpected size", len(data))
+		}
+
+		var decoded Measurement
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if decoded != m {
+			t.Error("round trip mismatch", m, decoded)
+		}
+	}
+}
+
+func TestMeasurementUnmarshalBinaryLayout(t *testing.T) {
+	data := []byte{
+		0x01,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06,
+		0x01, 0x9c,
+		0x00, 0x23,
+		0x5e, 0x0c, 0x6d, 0xa0,
+	}
+
+	var m Measurement
+	if err := m.UnmarshalBinary(data); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := Measurement{ECO2: 412, TVOC: 35, Timestamp: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), SerialID: 0x010203040506}
+	if m != expected {
+		t.Error("unexpected measurement", m)
+	}
+
+	encoded, err := expected.MarshalBinary()
+	if err != nil || !_bytesMatch(encoded, data) {
+		t.Errorf("unexpected encoding, %x, %s", encoded, err)
+	}
+}
+
+func TestMeasurementBinaryErrors(t *testing.T) {
+	if _, err := (Measurement{SerialID: 1 << 48}).MarshalBinary(); err == nil {
+		t.Error("expected error for oversized serial")
+	}
+
+	if _, err := (Measurement{Timestamp: time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)}).MarshalBinary(); err == nil {
+		t.Error("expected error for timestamp before the epoch")
+	}
+
+	var m Measurement
+	if err := m.UnmarshalBinary(make([]byte, MeasurementBinarySize-1)); err == nil {
+		t.Error("expected error for short record")
+	}
+
+	data := make([]byte, MeasurementBinarySize)
+	data[0] = 2
+	if err := m.UnmarshalBinary(data); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
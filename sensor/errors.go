@@ -0,0 +1,50 @@
+package sensor
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMeasureTooSoon   = errors.New("measurement requested before MinMeasureInterval elapsed")
+	ErrWrongSensorModel = errors.New("wrong sensor model")
+	ErrDeviceNotFound   = errors.New("i2c device not found")
+	ErrArbitrationLost  = errors.New("i2c arbitration lost")
+	ErrDiscarded        = errors.New("initial reading discarded")
+	ErrWrongSensor      = errors.New("connected to a different sensor than expected")
+	ErrBaselineStale    = errors.New("saved baseline is older than BaselineMaxAge")
+	ErrValueOutOfRange  = errors.New("measurement outside the sensor's output range")
+	ErrNoOpener         = errors.New("no Opener for a new connection, the sensor was given its connection")
+)
+
+// CRCError is returned when a reply word fails its checksum, which usually
+// means the bus corrupted the transfer.
+type CRCError struct {
+	Expected  byte
+	Got       byte
+	WordIndex int
+}
+
+func (e *CRCError) Error() string {
+	return fmt.Sprintf("crc mismatch on word %d: expected %x, got %x", e.WordIndex, e.Expected, e.Got)
+}
+
+// IsCRCError reports whether err is or wraps a CRCError.
+func IsCRCError(err error) bool {
+	var crcErr *CRCError
+	return errors.As(err, &crcErr)
+}
+
+// SerialReadError is returned by Init when the serial reply came back
+// corrupted, which points at bus trouble rather than a missing sensor.
+type SerialReadError struct {
+	Err error
+}
+
+func (e *SerialReadError) Error() string {
+	return fmt.Sprintf("corrupted serial read: %s", e.Err)
+}
+
+func (e *SerialReadError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,81 @@
+package sensor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	ProductTypeSGP30 uint8 = 0
+	ProductTypeSGPC3 uint8 = 1
+)
+
+type FeatureSet struct {
+	ProductType    uint8
+	ProductVersion uint8
+}
+
+// DeviceInfo identifies the sensor found by InitInfo.
+type DeviceInfo struct {
+	SerialID       uint64
+	ProductType    uint8
+	ProductVersion uint8
+}
+
+// InitInfo runs Init and returns the serial and feature set it read. SerialID
+// is still set on the sensor as with Init.
+func (s *SGP30Sensor) InitInfo() (DeviceInfo, error) {
+	if err := s.Init(); err != nil {
+		return DeviceInfo{}, err
+	}
+
+	return DeviceInfo{
+		SerialID:       s.SerialID,
+		ProductType:    s.featureSet.ProductType,
+		ProductVersion: s.featureSet.ProductVersion,
+	}, nil
+}
+
+// GetFeatureSet reads and decodes the feature set word. Init only requires
+// the SGP30 product type since the version byte varies between revisions.
+func (s *SGP30Sensor) GetFeatureSet() (FeatureSet, error) {
+	word, err := s.getFeatureSet(context.Background())
+	if err != nil {
+		return FeatureSet{}, err
+	}
+
+	return parseFeatureSet(word), nil
+}
+
+func parseFeatureSet(word uint16) FeatureSet {
+	return FeatureSet{
+		ProductType:    uint8(word >> 12),
+		ProductVersion: uint8(word),
+	}
+}
+
+func (f FeatureSet) Model() string {
+	switch f.ProductType {
+	case ProductTypeSGP30:
+		return "SGP30"
+	case ProductTypeSGPC3:
+		return "SGPC3"
+	default:
+		return fmt.Sprintf("unknown product type %d", f.ProductType)
+	}
+}
+
+// Fingerprint returns a short opaque ID derived from the serial and product
+// type, stable for a given device without exposing its serial.
+func (s *SGP30Sensor) Fingerprint() string {
+	var data [9]byte
+	binary.BigEndian.PutUint64(data[:8], s.SerialID)
+	data[8] = s.featureSet.ProductType
+
+	sum := sha256.Sum256(data[:])
+
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,100 @@
+package sensor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInitDetectsSGPC3(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x1006},
+	})
+
+	err := sensor.Init()
+	if !errors.Is(err, ErrWrongSensorModel) {
+		t.Fatal("expected wrong model error", err)
+	}
+
+	if !strings.Contains(err.Error(), "SGPC3") {
+		t.Error("expected error to name the detected model", err)
+	}
+}
+
+func TestInitAcceptsOtherSGP30Versions(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0022},
+	})
+
+	if err := sensor.Init(); err != nil {
+		t.Error("expected a newer SGP30 version to be accepted", err)
+	}
+}
+
+func TestInitInfo(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0022},
+	})
+
+	info, err := sensor.InitInfo()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := DeviceInfo{SerialID: 0x010203040506, ProductType: ProductTypeSGP30, ProductVersion: 0x22}
+	if info != expected {
+		t.Errorf("unexpected info, %+v", info)
+	}
+
+	if sensor.SerialID != expected.SerialID {
+		t.Error("expected SerialID to still be set", sensor.SerialID)
+	}
+
+	failing := NewSensor(DefaultConfig())
+	failing.cfg.Delay = 0
+	_mockResponses(failing, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x1006},
+	})
+
+	if info, err := failing.InitInfo(); err == nil || info != (DeviceInfo{}) {
+		t.Error("expected an empty info and error for the wrong model", info, err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	first := NewSensor(DefaultConfig())
+	first.SerialID = 0x010203040506
+
+	second := NewSensor(DefaultConfig())
+	second.SerialID = 0x010203040506
+
+	other := NewSensor(DefaultConfig())
+	other.SerialID = 0x010203040507
+
+	fingerprint := first.Fingerprint()
+	if len(fingerprint) != 16 {
+		t.Error("unexpected fingerprint length", fingerprint)
+	}
+
+	if second.Fingerprint() != fingerprint {
+		t.Error("expected same serial to share a fingerprint", fingerprint, second.Fingerprint())
+	}
+
+	if other.Fingerprint() == fingerprint {
+		t.Error("expected different serials to differ", fingerprint)
+	}
+
+	if strings.Contains(fingerprint, "10203040506") {
+		t.Error("expected fingerprint not to expose the serial", fingerprint)
+	}
+}
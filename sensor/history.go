@@ -0,0 +1,109 @@
+package sensor
+
+import "time"
+
+type measurementRing struct {
+	samples []Measurement
+	start   int
+	count   int
+}
+
+func (r *measurementRing) add(m Measurement, size int) {
+	if size <= 0 {
+		return
+	}
+
+	if len(r.samples) != size {
+		r.resize(size)
+	}
+
+	if r.count < size {
+		r.samples[(r.start+r.count)%size] = m
+		r.count++
+		return
+	}
+
+	r.samples[r.start] = m
+	r.start = (r.start + 1) % size
+}
+
+func (r *measurementRing) resize(size int) {
+	ordered := r.ordered()
+	if len(ordered) > size {
+		ordered = ordered[len(ordered)-size:]
+	}
+
+	r.samples = make([]Measurement, size)
+	copy(r.samples, ordered)
+	r.start = 0
+	r.count = len(ordered)
+}
+
+func (r *measurementRing) oldest() (Measurement, bool) {
+	if r.count == 0 {
+		return Measurement{}, false
+	}
+
+	return r.samples[r.start], true
+}
+
+func (r *measurementRing) dropOldest() {
+	if r.count == 0 {
+		return
+	}
+
+	r.start = (r.start + 1) % len(r.samples)
+	r.count--
+}
+
+func (r *measurementRing) ordered() []Measurement {
+	ordered := make([]Measurement, r.count)
+	for i := range ordered {
+		ordered[i] = r.samples[(r.start+i)%len(r.samples)]
+	}
+
+	return ordered
+}
+
+// Query returns the recorded measurements timestamped within [from, to],
+// oldest first. HistorySize bounds how far back it can reach.
+func (s *SGP30Sensor) Query(from, to time.Time) []Measurement {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	var results []Measurement
+	for _, m := range s.history.ordered() {
+		if !m.Timestamp.Before(from) && !m.Timestamp.After(to) {
+			results = append(results, m)
+		}
+	}
+
+	return results
+}
+
+func (s *SGP30Sensor) recordHistory(m Measurement, cfg *Config) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.history.add(m, cfg.HistorySize)
+	s.enforceBufferCap()
+}
+
+// enforceBufferCap evicts the oldest history and baseline samples until
+// together they fit in MaxBufferedSamples. The caller holds stateMu.
+func (s *SGP30Sensor) enforceBufferCap() {
+	maxSamples := s.config().MaxBufferedSamples
+	if maxSamples <= 0 {
+		return
+	}
+
+	for s.history.count+len(s.baselineHistory) > maxSamples {
+		oldest, ok := s.history.oldest()
+		if !ok || (len(s.baselineHistory) > 0 && s.baselineHistory[0].time.Before(oldest.Timestamp)) {
+			s.baselineHistory = s.baselineHistory[1:]
+			continue
+		}
+
+		s.history.dropOldest()
+	}
+}
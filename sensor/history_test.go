@@ -0,0 +1,86 @@
+package sensor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	sensor.cfg.HistorySize = 5
+	clock := _newFakeClock(sensor)
+	start := clock.Now()
+	_mockMeasurements(sensor, [][2]uint16{{400, 0}, {410, 1}, {420, 2}, {430, 3}, {440, 4}, {450, 5}, {460, 6}})
+
+	for i := 0; i < 7; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		clock.Advance(time.Second)
+	}
+
+	results := sensor.Query(start.Add(3*time.Second), start.Add(5*time.Second))
+	if len(results) != 3 {
+		t.Fatal("unexpected result count", 3, len(results))
+	}
+
+	for i, m := range results {
+		if m.ECO2 != uint16(430+10*i) || !m.Timestamp.Equal(start.Add(time.Duration(3+i)*time.Second)) {
+			t.Error("unexpected measurement", i, m)
+		}
+	}
+
+	if results := sensor.Query(start, start.Add(time.Second)); len(results) != 0 {
+		t.Error("expected evicted measurements to be gone", results)
+	}
+
+	if results := sensor.Query(start, start.Add(time.Hour)); len(results) != 5 || results[0].ECO2 != 420 || results[4].ECO2 != 460 {
+		t.Error("unexpected full history", results)
+	}
+}
+
+func TestMaxBufferedSamples(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	sensor.cfg.MaxBufferedSamples = 6
+	clock := _newFakeClock(sensor)
+	start := clock.Now()
+	_mockResponses(sensor, map[Command][]uint16{
+		MeasureAirQuality: {412, 3},
+		GetBaseline:       {0x8a00, 0x8b00},
+	})
+
+	for i := 0; i < 50; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if i%5 == 0 {
+			if _, _, err := sensor.GetBaseline(); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+		}
+
+		if total := sensor.history.count + len(sensor.baselineHistory); total > 6 {
+			t.Fatal("buffers exceeded the cap", i, total)
+		}
+
+		clock.Advance(time.Second)
+	}
+
+	history := sensor.Query(start, clock.Now())
+	if len(history)+len(sensor.baselineHistory) != 6 {
+		t.Error("expected buffers filled to the cap", len(history), len(sensor.baselineHistory))
+	}
+
+	if len(history) == 0 || !history[len(history)-1].Timestamp.Equal(start.Add(49*time.Second)) {
+		t.Error("expected the newest measurement to be kept", history)
+	}
+
+	if len(sensor.baselineHistory) == 0 || !sensor.baselineHistory[0].time.After(history[0].Timestamp.Add(-5*time.Second)) {
+		t.Error("expected the oldest samples evicted first", sensor.baselineHistory)
+	}
+}
@@ -0,0 +1,89 @@
+package sensor
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxAbsoluteHumidity is the largest value the 8.8 fixed point humidity word
+// can hold, in g/m^3.
+const MaxAbsoluteHumidity = float64(math.MaxUint16) / 256
+
+// SetHumidity sets the absolute humidity in g/m^3 used for compensation.
+// Zero disables compensation.
+func (s *SGP30Sensor) SetHumidity(absHumidity float64) error {
+	if !(absHumidity >= 0 && absHumidity <= MaxAbsoluteHumidity) {
+		return fmt.Errorf("absolute humidity %f outside 0 to %f g/m^3", absHumidity, MaxAbsoluteHumidity)
+	}
+
+	word := humidityWord(absHumidity)
+
+	if _, err := s.readWords(CommandBytes(SetHumidity, word), 0); err != nil {
+		return err
+	}
+
+	s.setHumidityCompensation(word)
+
+	return nil
+}
+
+// SetHumidityFromAmbient sets the humidity compensation from a companion
+// sensor's temperature and relative humidity.
+func (s *SGP30Sensor) SetHumidityFromAmbient(tempC, relHumidity float64) error {
+	return s.SetHumidity(AbsoluteHumidity(tempC, relHumidity))
+}
+
+// CurrentHumidityCompensation returns the last absolute humidity word written
+// with SetHumidity. The sensor has no command to read it back.
+func (s *SGP30Sensor) CurrentHumidityCompensation() (uint16, bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	return s.humidity, s.humiditySet
+}
+
+func (s *SGP30Sensor) setHumidityCompensation(word uint16) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.humidity = word
+	s.humiditySet = true
+}
+
+func humidityWord(absHumidity float64) uint16 {
+	return uint16(math.Round(absHumidity * 256))
+}
+
+// AbsoluteHumidity converts temperature and relative humidity to absolute
+// humidity in g/m^3 using the Magnus approximation.
+func AbsoluteHumidity(tempC float64, relHumidity float64) float64 {
+	saturation := 6.112 * math.Exp((17.62*tempC)/(243.12+tempC))
+
+	return 216.7 * (relHumidity / 100 * saturation) / (273.15 + tempC)
+}
+
+// AbsoluteHumidityFromDewPoint returns the 8.8 fixed point absolute humidity
+// of air whose dew point is dewPointC, i.e. saturated air at that temperature.
+func AbsoluteHumidityFromDewPoint(dewPointC float64) uint16 {
+	return humidityWord(AbsoluteHumidity(dewPointC, 100))
+}
+
+func (s *SGP30Sensor) applyAutoHumidity(cfg *Config) {
+	if cfg.AutoHumidityFunc == nil {
+		return
+	}
+
+	relHumidity, tempC, ok := cfg.AutoHumidityFunc()
+	if !ok {
+		return
+	}
+
+	absHumidity := AbsoluteHumidity(tempC, relHumidity)
+	if current, set := s.CurrentHumidityCompensation(); set && current == humidityWord(absHumidity) {
+		return
+	}
+
+	if err := s.SetHumidity(absHumidity); err != nil {
+		s.logError("failed to apply auto humidity: %s", err)
+	}
+}
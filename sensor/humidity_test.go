@@ -0,0 +1,220 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestSetHumidity(t *testing.T) {
+	table := []struct {
+		absHumidity float64
+		expected    uint16
+		valid       bool
+	}{
+		{0, 0x0000, true},
+		{15.5, 0x0f80, true},
+		{MaxAbsoluteHumidity, 0xffff, true},
+		{-0.5, 0, false},
+		{256, 0, false},
+		{math.NaN(), 0, false},
+	}
+
+	for _, row := range table {
+		sensor := NewSensor(DefaultConfig())
+		sensor.cfg.Delay = 0
+
+		var written []byte
+		sensor.i2cConnection = &_mockI2cConnection{
+			writeClosure: func(buf []byte) error {
+				written = buf
+				return nil
+			},
+		}
+
+		err := sensor.SetHumidity(row.absHumidity)
+		if !row.valid {
+			if err == nil || written != nil {
+				t.Error("expected out of range error", row.absHumidity, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Error("unexpected error", row.absHumidity, err)
+			continue
+		}
+
+		if !_bytesMatch(written, CommandBytes(SetHumidity, row.expected)) {
+			t.Errorf("unexpected frame for %f, %x", row.absHumidity, written)
+		}
+	}
+}
+
+func TestCurrentHumidityCompensation(t *testing.T) {
+	mock := &_mockI2cConnection{}
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.i2cConnection = mock
+
+	if _, ok := sensor.CurrentHumidityCompensation(); ok {
+		t.Error("expected humidity to be unset")
+	}
+
+	mock.writeClosure = func(buf []byte) error {
+		if !_bytesMatch(buf, []byte{0x20, 0x61, 0x0f, 0x80, 0x62}) {
+			t.Error("unexpected buffer", buf)
+		}
+
+		return nil
+	}
+
+	if err := sensor.SetHumidity(15.5); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	humidity, ok := sensor.CurrentHumidityCompensation()
+	if !ok || humidity != 0x0f80 {
+		t.Errorf("unexpected humidity compensation, %x, %t", humidity, ok)
+	}
+}
+
+func TestHumidityCompensationConcurrent(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	_newFakeClock(sensor)
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error { return nil },
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			sensor.SetHumidity(float64(i))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		sensor.CurrentHumidityCompensation()
+	}
+
+	<-done
+
+	if humidity, ok := sensor.CurrentHumidityCompensation(); !ok || humidity != humidityWord(99) {
+		t.Errorf("unexpected humidity compensation, %x, %t", humidity, ok)
+	}
+}
+
+func TestAbsoluteHumidityFromDewPoint(t *testing.T) {
+	table := []struct {
+		dewPointC float64
+		expected  uint16
+	}{
+		{-10, 0x025d},
+		{0, 0x04d9},
+		{10, 0x0962},
+		{20, 0x113e},
+	}
+
+	for _, row := range table {
+		if word := AbsoluteHumidityFromDewPoint(row.dewPointC); word != row.expected {
+			t.Errorf("unexpected humidity at %f, %x, %x", row.dewPointC, row.expected, word)
+		}
+	}
+}
+
+func TestAutoHumidity(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	humidityOK := true
+	sensor.cfg.AutoHumidityFunc = func() (float64, float64, bool) {
+		return 50, 25, humidityOK
+	}
+
+	expectedWord := humidityWord(AbsoluteHumidity(25, 50))
+	writeClosure := mock.writeClosure
+	var commands []Command
+	mock.writeClosure = func(buf []byte) error {
+		command := Command(binary.BigEndian.Uint16(buf))
+		commands = append(commands, command)
+
+		if command == SetHumidity && binary.BigEndian.Uint16(buf[2:]) != expectedWord {
+			t.Errorf("unexpected humidity word, %x, %x", expectedWord, buf[2:4])
+		}
+
+		return writeClosure(buf)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	humidityOK = false
+	sensor.humiditySet = false
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := []Command{SetHumidity, MeasureAirQuality, MeasureAirQuality, MeasureAirQuality}
+	if len(commands) != len(expected) {
+		t.Fatal("unexpected commands", commands)
+	}
+
+	for i := range expected {
+		if commands[i] != expected[i] {
+			t.Errorf("unexpected command %d, %x, %x", i, expected[i], commands[i])
+		}
+	}
+}
+
+func TestAbsoluteHumidity(t *testing.T) {
+	table := []struct {
+		tempC       float64
+		relHumidity float64
+		expected    float64
+	}{
+		{25, 50, 11.5},
+		{20, 50, 8.6},
+		{0, 100, 4.8},
+		{30, 80, 24.3},
+		{25, 0, 0},
+	}
+
+	for _, row := range table {
+		if absHumidity := AbsoluteHumidity(row.tempC, row.relHumidity); math.Abs(absHumidity-row.expected) > 0.1 {
+			t.Errorf("unexpected absolute humidity at %.0fC/%.0f%%, %.2f, %.2f", row.tempC, row.relHumidity, row.expected, absHumidity)
+		}
+	}
+}
+
+func TestSetHumidityFromAmbient(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+
+	var written []byte
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			written = buf
+			return nil
+		},
+	}
+
+	if err := sensor.SetHumidityFromAmbient(25, 50); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !_bytesMatch(written, CommandBytes(SetHumidity, humidityWord(AbsoluteHumidity(25, 50)))) {
+		t.Errorf("unexpected frame, %x", written)
+	}
+
+	if humidity, _ := sensor.CurrentHumidityCompensation(); humidity>>8 != 11 {
+		t.Errorf("expected about 11.5 g/m^3, %x", humidity)
+	}
+}
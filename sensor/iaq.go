@@ -0,0 +1,94 @@
+package sensor
+
+import (
+	"context"
+	"time"
+)
+
+// AirQuality is a coarse indoor air quality category.
+type AirQuality int
+
+const (
+	Excellent AirQuality = iota
+	Good
+	Moderate
+	Poor
+)
+
+func (q AirQuality) String() string {
+	switch q {
+	case Excellent:
+		return "excellent"
+	case Good:
+		return "good"
+	case Moderate:
+		return "moderate"
+	case Poor:
+		return "poor"
+	default:
+		return "unknown"
+	}
+}
+
+// IAQThresholds are the upper bounds, exclusive, of the Excellent, Good and
+// Moderate categories. Anything at or above the last bound is Poor.
+type IAQThresholds struct {
+	ECO2 [3]uint16
+	TVOC [3]uint16
+}
+
+// DefaultIAQThresholds follows common indoor guidance: the usual CO2
+// ventilation levels and the German UBA TVOC levels.
+var DefaultIAQThresholds = IAQThresholds{
+	ECO2: [3]uint16{600, 1000, 1500},
+	TVOC: [3]uint16{65, 220, 660},
+}
+
+type IAQReading struct {
+	ECO2      uint16
+	TVOC      uint16
+	Timestamp time.Time
+	// Category is the worse of the eCO2 and TVOC categories.
+	Category AirQuality
+}
+
+// Classify returns the worse of the eCO2 and TVOC categories.
+func (t IAQThresholds) Classify(eCO2, TVOC uint16) AirQuality {
+	eco2Category := classify(eCO2, t.ECO2)
+	if tvocCategory := classify(TVOC, t.TVOC); tvocCategory > eco2Category {
+		return tvocCategory
+	}
+
+	return eco2Category
+}
+
+func classify(value uint16, bounds [3]uint16) AirQuality {
+	for i, bound := range bounds {
+		if value < bound {
+			return AirQuality(i)
+		}
+	}
+
+	return Poor
+}
+
+// MeasureIAQ measures and classifies the reading with Config.IAQThresholds,
+// or DefaultIAQThresholds when they are unset.
+func (s *SGP30Sensor) MeasureIAQ() (IAQReading, error) {
+	m, err := s.measureChecked(context.Background())
+	if err != nil {
+		return IAQReading{}, err
+	}
+
+	thresholds := s.config().IAQThresholds
+	if thresholds == (IAQThresholds{}) {
+		thresholds = DefaultIAQThresholds
+	}
+
+	return IAQReading{
+		ECO2:      m.ECO2,
+		TVOC:      m.TVOC,
+		Timestamp: m.Timestamp,
+		Category:  thresholds.Classify(m.ECO2, m.TVOC),
+	}, nil
+}
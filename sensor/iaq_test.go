@@ -0,0 +1,61 @@
+package sensor
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	table := []struct {
+		eCO2     uint16
+		TVOC     uint16
+		expected AirQuality
+	}{
+		{400, 0, Excellent},
+		{599, 64, Excellent},
+		{600, 0, Good},
+		{400, 65, Good},
+		{999, 219, Good},
+		{1000, 0, Moderate},
+		{400, 220, Moderate},
+		{1499, 659, Moderate},
+		{1500, 0, Poor},
+		{400, 660, Poor},
+		{60000, 60000, Poor},
+		{1200, 100, Moderate},
+		{500, 1000, Poor},
+	}
+
+	for _, row := range table {
+		if category := DefaultIAQThresholds.Classify(row.eCO2, row.TVOC); category != row.expected {
+			t.Errorf("unexpected category for %d ppm, %d ppb: %s, %s", row.eCO2, row.TVOC, row.expected, category)
+		}
+	}
+}
+
+func TestMeasureIAQ(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{800, 35}})
+
+	reading, err := sensor.MeasureIAQ()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if reading.ECO2 != 800 || reading.TVOC != 35 || reading.Category != Good || reading.Timestamp.IsZero() {
+		t.Errorf("unexpected reading, %+v", reading)
+	}
+
+	sensor.cfg.IAQThresholds = IAQThresholds{
+		ECO2: [3]uint16{500, 700, 900},
+		TVOC: [3]uint16{10, 20, 30},
+	}
+
+	if reading, _ := sensor.MeasureIAQ(); reading.Category != Poor {
+		t.Error("expected the configured thresholds", reading.Category)
+	}
+
+	sensor.cfg.IAQThresholds = IAQThresholds{}
+
+	if reading, _ := sensor.MeasureIAQ(); reading.Category != Good {
+		t.Error("expected the defaults for unset thresholds", reading.Category)
+	}
+}
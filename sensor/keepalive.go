@@ -0,0 +1,99 @@
+package sensor
+
+import (
+	"context"
+	"fmt"
+)
+
+// Reconnect drops the current connection and opens a fresh one. A sensor given
+// its connection with NewSensorWithConnection or WithConnection has no way to
+// open another unless WithOpener was also used, and returns ErrNoOpener
+// without closing the connection.
+func (s *SGP30Sensor) Reconnect() error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	return s.reconnect(context.Background())
+}
+
+// reconnect is Reconnect for callers already holding the transaction lock.
+// The sensor is Connecting meanwhile, then back in its previous state or
+// Disconnected if no connection could be opened.
+func (s *SGP30Sensor) reconnect(ctx context.Context) error {
+	if s.opener == nil {
+		return ErrNoOpener
+	}
+
+	previous := s.State()
+	s.setState(Connecting)
+
+	if s.i2cConnection != nil {
+		if err := s.i2cConnection.Close(); err != nil {
+			s.logError("failed closing connection for reconnect: %s", err)
+		}
+		s.i2cConnection = nil
+	}
+
+	if err := s.startI2CConnection(ctx); err != nil {
+		s.setState(Disconnected)
+		return err
+	}
+
+	s.setState(previous)
+
+	return nil
+}
+
+func (s *SGP30Sensor) startKeepalive() {
+	interval := s.config().KeepaliveInterval
+	if interval <= 0 {
+		return
+	}
+
+	s.bgMu.Lock()
+	running := s.keepaliveRunning
+	s.keepaliveRunning = true
+	s.bgMu.Unlock()
+
+	if running {
+		return
+	}
+
+	s.goBackground(func(ctx context.Context) {
+		defer func() {
+			s.bgMu.Lock()
+			s.keepaliveRunning = false
+			s.bgMu.Unlock()
+		}()
+
+		for s.sleepContext(ctx, interval) == nil {
+			if _, err := s.readWordsUintContext(ctx, GetFeatureSetVersion, 1); err != nil && ctx.Err() == nil {
+				s.logError("keepalive failed, reconnecting: %s", err)
+				if err := s.Reconnect(); err != nil {
+					s.logError("keepalive reconnect failed: %s", err)
+				}
+			}
+		}
+	})
+}
+
+// Ping checks the sensor still answers by re-reading its serial, returning
+// ErrWrongSensor if it no longer matches SerialID, e.g. when a mux switched
+// to another device. Without a serial from Init it reads the feature set.
+func (s *SGP30Sensor) Ping() error {
+	if s.SerialID == 0 {
+		_, err := s.getFeatureSet(context.Background())
+		return err
+	}
+
+	serial, err := s.getSerial(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if serial != s.SerialID {
+		return fmt.Errorf("%w: expected serial %x, found %x", ErrWrongSensor, s.SerialID, serial)
+	}
+
+	return nil
+}
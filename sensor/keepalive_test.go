@@ -0,0 +1,262 @@
+package sensor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestKeepalive(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.KeepaliveInterval = time.Millisecond
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+
+	var mu sync.Mutex
+	probes := 0
+	writeClosure := mock.writeClosure
+	mock.writeClosure = func(buf []byte) error {
+		if _bytesMatchUint(buf, GetFeatureSetVersion) {
+			mu.Lock()
+			probes++
+			mu.Unlock()
+		}
+
+		return writeClosure(buf)
+	}
+	mock.closeClosure = func() error {
+		return nil
+	}
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	probeCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return probes
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for probeCount() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if probeCount() < 4 {
+		t.Fatal("expected periodic keepalive probes", probeCount())
+	}
+
+	if err := sensor.Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	stopped := probeCount()
+	time.Sleep(10 * time.Millisecond)
+
+	if probeCount() != stopped {
+		t.Error("expected keepalive to stop on close")
+	}
+}
+
+func TestKeepaliveReconnects(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.KeepaliveInterval = time.Millisecond
+	fresh := _mockResponses(sensor, map[Command][]uint16{
+		GetFeatureSetVersion: {0x0020},
+	})
+	fresh.closeClosure = func() error {
+		return nil
+	}
+
+	reconnected := make(chan struct{})
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		openClosure: func(path string, addr byte) (Connection, error) {
+			close(reconnected)
+			return fresh, nil
+		},
+	}
+
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			return fmt.Errorf("link down")
+		},
+		closeClosure: func() error {
+			return nil
+		},
+	}
+	sensor.startKeepalive()
+
+	select {
+	case <-reconnected:
+	case <-time.After(time.Second):
+		t.Fatal("expected keepalive failure to reconnect")
+	}
+
+	if err := sensor.Close(); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+
+	fresh := _mockMeasurements(NewSensor(DefaultConfig()), [][2]uint16{{412, 35}})
+
+	opened := 0
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		openClosure: func(path string, addr byte) (Connection, error) {
+			opened++
+			return fresh, nil
+		},
+	}
+
+	closed := 0
+	unplugged := func() *_mockI2cConnection {
+		return &_mockI2cConnection{
+			writeClosure: func(buf []byte) error {
+				return &os.PathError{Op: "write", Path: DefaultI2CFsPath, Err: syscall.ENODEV}
+			},
+			closeClosure: func() error {
+				closed++
+				return nil
+			},
+		}
+	}
+
+	sensor.i2cConnection = unplugged()
+
+	if _, _, err := sensor.Measure(); !errors.Is(err, syscall.ENODEV) {
+		t.Fatal("expected the write error without AutoReconnect", err)
+	}
+
+	if err := sensor.Reconnect(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if opened != 1 || closed != 1 || sensor.i2cConnection != fresh {
+		t.Fatal("expected the old connection swapped for a fresh one", opened, closed)
+	}
+
+	if eCO2, TVOC, err := sensor.Measure(); err != nil || eCO2 != 412 || TVOC != 35 {
+		t.Error("unexpected measurement after reconnect", eCO2, TVOC, err)
+	}
+
+	sensor.cfg.AutoReconnect = true
+	sensor.i2cConnection = unplugged()
+
+	if eCO2, TVOC, err := sensor.Measure(); err != nil || eCO2 != 412 || TVOC != 35 {
+		t.Error("expected AutoReconnect to repeat the measurement", eCO2, TVOC, err)
+	}
+
+	if opened != 2 || closed != 2 {
+		t.Error("expected one automatic reconnect", opened, closed)
+	}
+}
+
+func TestReconnectInjectedConnection(t *testing.T) {
+	closed := 0
+	conn := &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			return &os.PathError{Op: "write", Path: DefaultI2CFsPath, Err: syscall.ENODEV}
+		},
+		closeClosure: func() error {
+			closed++
+			return nil
+		},
+	}
+
+	cfg := DefaultConfig()
+	cfg.AutoReconnect = true
+	sensor := NewSensorWithConnection(cfg, conn)
+	_newFakeClock(sensor)
+
+	if _, _, err := sensor.Measure(); !errors.Is(err, syscall.ENODEV) {
+		t.Error("expected the write error without reconnecting", err)
+	}
+
+	if err := sensor.Reconnect(); err != ErrNoOpener {
+		t.Error("expected no opener error", err)
+	}
+
+	if closed != 0 || sensor.i2cConnection != conn {
+		t.Error("expected the caller's connection to be kept open", closed)
+	}
+
+	fresh := _mockMeasurements(NewSensor(DefaultConfig()), [][2]uint16{{412, 35}})
+	opened := 0
+	sensor = NewSensorWithOptions(
+		WithConfig(cfg),
+		WithConnection(conn),
+		WithOpener(&_mockOpener{
+			statClosure: func(name string) (os.FileInfo, error) {
+				return nil, nil
+			},
+			openClosure: func(path string, addr byte) (Connection, error) {
+				opened++
+				return fresh, nil
+			},
+		}),
+	)
+	_newFakeClock(sensor)
+
+	if eCO2, TVOC, err := sensor.Measure(); err != nil || eCO2 != 412 || TVOC != 35 {
+		t.Error("expected AutoReconnect through the given opener", eCO2, TVOC, err)
+	}
+
+	if opened != 1 || closed != 1 {
+		t.Error("expected one reconnect", opened, closed)
+	}
+}
+
+func TestPing(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	_newFakeClock(sensor)
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+
+	if err := sensor.Ping(); err != nil {
+		t.Error("expected ping without a serial to read the feature set", err)
+	}
+
+	sensor.SerialID = 0x010203040506
+
+	if err := sensor.Ping(); err != nil {
+		t.Error("unexpected error for a matching serial", err)
+	}
+
+	sensor.SerialID = 0x010203040507
+
+	if err := sensor.Ping(); !errors.Is(err, ErrWrongSensor) {
+		t.Error("expected wrong sensor error for a changed serial", err)
+	}
+
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			return errors.New("nak")
+		},
+	}
+
+	if err := sensor.Ping(); err == nil || errors.Is(err, ErrWrongSensor) {
+		t.Error("expected the bus error when the sensor doesn't answer", err)
+	}
+}
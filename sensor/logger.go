@@ -0,0 +1,17 @@
+package sensor
+
+// Logger is the leveled, printf-style logger the sensor writes to. A
+// *logging.Logger from github.com/op/go-logging satisfies it, and adapters for
+// other libraries only need these three methods.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// NopLogger discards everything. It is the default when no logger is set.
+type NopLogger struct{}
+
+func (NopLogger) Debugf(format string, args ...interface{}) {}
+func (NopLogger) Infof(format string, args ...interface{})  {}
+func (NopLogger) Errorf(format string, args ...interface{}) {}
@@ -0,0 +1,106 @@
+package sensor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type _captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *_captureLogger) log(level string, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lines = append(l.lines, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *_captureLogger) Debugf(format string, args ...interface{}) {
+	l.log("DEBUG", format, args...)
+}
+
+func (l *_captureLogger) Infof(format string, args ...interface{}) {
+	l.log("INFO", format, args...)
+}
+
+func (l *_captureLogger) Errorf(format string, args ...interface{}) {
+	l.log("ERROR", format, args...)
+}
+
+func TestCustomLogger(t *testing.T) {
+	logger := &_captureLogger{}
+	sensor := NewSensorWithOptions(WithLogger(logger))
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	for i := 0; i < 2; i++ {
+		clock.Advance(3 * time.Second)
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	expected := "INFO measured off the 1s cadence the baseline algorithm expects"
+	if len(logger.lines) != 1 || logger.lines[0] != expected {
+		t.Error("unexpected log lines", logger.lines)
+	}
+}
+
+func TestNilLoggerDefaultsToNop(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Logger = nil
+
+	sensor := NewSensor(cfg)
+	if _, ok := sensor.cfg.Logger.(NopLogger); !ok {
+		t.Error("expected the no-op logger", sensor.cfg.Logger)
+	}
+
+	if err := sensor.UpdateConfig(func(cfg *Config) { cfg.Logger = nil }); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, ok := sensor.cfg.Logger.(NopLogger); !ok {
+		t.Error("expected the no-op logger after an update", sensor.cfg.Logger)
+	}
+
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestLogErrorFormatsParams(t *testing.T) {
+	logger := &_captureLogger{}
+	sensor := NewSensorWithOptions(WithLogger(logger))
+	_newFakeClock(sensor)
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			return nil
+		},
+		readClosure: func(buf []byte) error {
+			return errors.New("bus fault")
+		},
+	}
+
+	sensor.Measure()
+
+	sensor.logDebug("debug %d %x", 12, 0xab)
+
+	expected := []string{"ERROR failed read: bus fault", "DEBUG debug 12 ab"}
+	if len(logger.lines) != len(expected) {
+		t.Fatal("unexpected log lines", logger.lines)
+	}
+
+	for i := range expected {
+		if logger.lines[i] != expected[i] {
+			t.Errorf("unexpected log line %q, %q", expected[i], logger.lines[i])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package sensor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Manager runs several sensors that share one bus, such as four SGP30s behind
+// a TCA9548A multiplexer. Operations go through one lock so only one sensor
+// talks at a time, and the select callback runs first to point the mux at it.
+//
+// Only calls made through the Manager take its lock. Managed sensors must not
+// run their own background loops, such as StartMeasuring, MeasureOnSignal,
+// StreamSensirionLog or the Config.KeepaliveInterval keepalive, as those would
+// talk on whichever channel happens to be selected. Call MeasureAll on a
+// ticker instead.
+type Manager struct {
+	mu            sync.Mutex
+	sensors       map[string]*SGP30Sensor
+	selectChannel func(label string) error
+}
+
+// NewManager builds an empty manager. selectChannel may be nil when the
+// sensors don't sit behind a mux.
+func NewManager(selectChannel func(label string) error) *Manager {
+	return &Manager{
+		sensors:       make(map[string]*SGP30Sensor),
+		selectChannel: selectChannel,
+	}
+}
+
+// Add registers sensor under label, replacing any sensor already there.
+func (m *Manager) Add(label string, sensor *SGP30Sensor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sensors[label] = sensor
+}
+
+// Labels returns the registered labels in sorted order.
+func (m *Manager) Labels() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.labels()
+}
+
+func (m *Manager) labels() []string {
+	labels := make([]string, 0, len(m.sensors))
+	for label := range m.sensors {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	return labels
+}
+
+// Do selects the sensor's channel and runs fn while holding the bus.
+func (m *Manager) Do(label string, fn func(s *SGP30Sensor) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.do(label, fn)
+}
+
+func (m *Manager) do(label string, fn func(s *SGP30Sensor) error) error {
+	sensor, ok := m.sensors[label]
+	if !ok {
+		return fmt.Errorf("no sensor labelled %q", label)
+	}
+
+	if m.selectChannel != nil {
+		if err := m.selectChannel(label); err != nil {
+			return fmt.Errorf("failed to select channel for %q: %w", label, err)
+		}
+	}
+
+	return fn(sensor)
+}
+
+// MeasureAll measures every sensor in label order. Sensors that fail are left
+// out of the readings and reported in errs instead.
+func (m *Manager) MeasureAll() (readings map[string]Measurement, errs map[string]error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	readings = make(map[string]Measurement, len(m.sensors))
+	errs = make(map[string]error)
+
+	for _, label := range m.labels() {
+		err := m.do(label, func(s *SGP30Sensor) error {
+			reading, err := s.MeasureReading()
+			if err == nil {
+				readings[label] = reading
+			}
+
+			return err
+		})
+		if err != nil {
+			errs[label] = err
+		}
+	}
+
+	return readings, errs
+}
@@ -0,0 +1,60 @@
+package sensor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestManagerMeasureAll(t *testing.T) {
+	var selected []string
+	manager := NewManager(func(label string) error {
+		selected = append(selected, label)
+		if label == "mux-down" {
+			return errors.New("mux nak")
+		}
+
+		return nil
+	})
+
+	kitchen := NewSensor(DefaultConfig())
+	kitchen.cfg.Name = "kitchen"
+	_newFakeClock(kitchen)
+	_mockMeasurements(kitchen, [][2]uint16{{412, 35}})
+
+	bedroom := NewSensor(DefaultConfig())
+	_newFakeClock(bedroom)
+	bedroom.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			return errors.New("bus fault")
+		},
+	}
+
+	manager.Add("kitchen", kitchen)
+	manager.Add("bedroom", bedroom)
+	manager.Add("mux-down", NewSensor(DefaultConfig()))
+
+	readings, errs := manager.MeasureAll()
+
+	expectedOrder := []string{"bedroom", "kitchen", "mux-down"}
+	if len(selected) != len(expectedOrder) {
+		t.Fatal("unexpected channel selections", selected)
+	}
+
+	for i := range expectedOrder {
+		if selected[i] != expectedOrder[i] {
+			t.Error("unexpected selection order", selected)
+		}
+	}
+
+	if len(readings) != 1 || readings["kitchen"].ECO2 != 412 || readings["kitchen"].SensorName != "kitchen" {
+		t.Error("unexpected readings", readings)
+	}
+
+	if len(errs) != 2 || errs["bedroom"] == nil || errs["mux-down"] == nil {
+		t.Error("expected errors for the failing sensors", errs)
+	}
+
+	if err := manager.Do("attic", func(s *SGP30Sensor) error { return nil }); err == nil {
+		t.Error("expected error for an unknown label")
+	}
+}
@@ -0,0 +1,136 @@
+package sensor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	MinECO2 uint16 = 400
+	MaxECO2 uint16 = 60000
+	MaxTVOC uint16 = 60000
+)
+
+type Measurement struct {
+	ECO2       uint16    `json:"eco2_ppm"`
+	TVOC       uint16    `json:"tvoc_ppb"`
+	Timestamp  time.Time `json:"timestamp"`
+	SerialID   uint64    `json:"serial_id,omitempty"`
+	SensorName string    `json:"sensor_name,omitempty"`
+	// InWarmup is set on readings taken within WarmupDuration of Init, which
+	// are the fixed 400 ppm / 0 ppb placeholders.
+	InWarmup bool `json:"in_warmup,omitempty"`
+	// OffCadence is set when the gap since the previous reading was outside
+	// CadenceMinInterval and CadenceMaxInterval.
+	OffCadence bool `json:"off_cadence,omitempty"`
+}
+
+// checkRange returns ErrValueOutOfRange unless eCO2 and TVOC are within the
+// datasheet output range.
+func checkRange(eCO2, TVOC uint16) error {
+	if eCO2 < MinECO2 || eCO2 > MaxECO2 {
+		return fmt.Errorf("%w: eCO2 %d ppm", ErrValueOutOfRange, eCO2)
+	}
+
+	if TVOC > MaxTVOC {
+		return fmt.Errorf("%w: TVOC %d ppb", ErrValueOutOfRange, TVOC)
+	}
+
+	return nil
+}
+
+func (m Measurement) Plausible() bool {
+	return m.ECO2 >= MinECO2 && m.ECO2 <= MaxECO2 && m.TVOC <= MaxTVOC
+}
+
+// LastRawMeasurement returns the latest reading as the sensor reported it,
+// before quantization.
+func (s *SGP30Sensor) LastRawMeasurement() (Measurement, bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	return s.lastRaw, !s.lastRaw.Timestamp.IsZero()
+}
+
+func (s *SGP30Sensor) LastMeasurement() (Measurement, bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	return s.last, !s.last.Timestamp.IsZero()
+}
+
+func (s *SGP30Sensor) process(raw Measurement, cfg *Config) Measurement {
+	m := raw
+	if cfg.SmoothingWindow > 0 {
+		s.stateMu.Lock()
+		m = s.smoothing.add(m, cfg.SmoothingWindow)
+		s.stateMu.Unlock()
+	}
+
+	if cfg.PostProcess != nil {
+		m = cfg.PostProcess(m)
+	}
+
+	m.ECO2 = quantize(m.ECO2, cfg.QuantizeECO2)
+	m.TVOC = quantize(m.TVOC, cfg.QuantizeTVOC)
+
+	s.stateMu.Lock()
+	s.lastRaw = raw
+	s.last = m
+	s.stateMu.Unlock()
+
+	return m
+}
+
+func quantize(value uint16, step uint16) uint16 {
+	if step == 0 {
+		return value
+	}
+
+	rounded := (uint32(value) + uint32(step)/2) / uint32(step) * uint32(step)
+	if rounded > math.MaxUint16 {
+		rounded -= uint32(step)
+	}
+
+	return uint16(rounded)
+}
+
+// placeholder reports whether m holds the fixed values the sensor returns
+// while its algorithm is still starting up.
+func (m Measurement) placeholder() bool {
+	return m.ECO2 == MinECO2 && m.TVOC == 0
+}
+
+// MeasureInto measures like Measure but writes eCO2 and TVOC into dst without
+// allocating.
+func (s *SGP30Sensor) MeasureInto(dst *[2]uint16) error {
+	m, err := s.measureChecked(context.Background())
+	if err != nil {
+		return err
+	}
+
+	dst[0], dst[1] = m.ECO2, m.TVOC
+
+	return nil
+}
+
+// MeasureReading measures like Measure but returns the whole Measurement.
+func (s *SGP30Sensor) MeasureReading() (Measurement, error) {
+	return s.measureChecked(context.Background())
+}
+
+// MeasureAll reads the air quality values and then the raw signals, each
+// command waiting its own delay before the reply.
+func (s *SGP30Sensor) MeasureAll() (eCO2, TVOC, h2, ethanol uint16, err error) {
+	if eCO2, TVOC, err = s.Measure(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	if h2, ethanol, err = s.MeasureRaw(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	return eCO2, TVOC, h2, ethanol, nil
+}
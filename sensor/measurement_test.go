@@ -0,0 +1,362 @@
+package sensor
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuantize(t *testing.T) {
+	table := []struct {
+		value    uint16
+		step     uint16
+		expected uint16
+	}{
+		{412, 0, 412},
+		{412, 50, 400},
+		{425, 50, 450},
+		{449, 50, 450},
+		{37, 10, 40},
+		{65535, 50, 65500},
+	}
+
+	for _, row := range table {
+		if quantized := quantize(row.value, row.step); quantized != row.expected {
+			t.Errorf("unexpected quantization of %d by %d, %d, %d", row.value, row.step, row.expected, quantized)
+		}
+	}
+}
+
+func TestMeasureQuantization(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.QuantizeECO2 = 50
+	_mockMeasurements(sensor, [][2]uint16{{412, 37}})
+
+	if _, ok := sensor.LastRawMeasurement(); ok {
+		t.Error("expected no raw measurement yet")
+	}
+
+	eCO2, TVOC, err := sensor.Measure()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if eCO2 != 400 || TVOC != 37 {
+		t.Error("unexpected quantized values", eCO2, TVOC)
+	}
+
+	raw, ok := sensor.LastRawMeasurement()
+	if !ok || raw.ECO2 != 412 || raw.TVOC != 37 {
+		t.Error("unexpected raw measurement", raw, ok)
+	}
+}
+
+func TestPostProcess(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.PostProcess = func(m Measurement) Measurement {
+		m.ECO2 += 100
+		return m
+	}
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	eCO2, TVOC, err := sensor.Measure()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if eCO2 != 512 || TVOC != 35 {
+		t.Error("unexpected returned values", eCO2, TVOC)
+	}
+
+	cached, ok := sensor.LastMeasurement()
+	if !ok || cached.ECO2 != 512 || cached.TVOC != 35 {
+		t.Error("unexpected cached measurement", cached, ok)
+	}
+
+	if raw, _ := sensor.LastRawMeasurement(); raw.ECO2 != 412 {
+		t.Error("expected raw measurement to be untouched", raw)
+	}
+}
+
+func TestMeasureInto(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_mockMeasurements(sensor, [][2]uint16{{412, 37}, {420, 12}})
+
+	var dst [2]uint16
+	if err := sensor.MeasureInto(&dst); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if dst != [2]uint16{412, 37} {
+		t.Error("unexpected measurement", dst)
+	}
+
+	if err := sensor.MeasureInto(&dst); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if dst != [2]uint16{420, 12} {
+		t.Error("unexpected measurement", dst)
+	}
+
+	if last, ok := sensor.LastMeasurement(); !ok || last.ECO2 != 420 || last.TVOC != 12 {
+		t.Error("expected MeasureInto to update the last measurement", last)
+	}
+}
+
+func TestMeasureIntoAllocations(t *testing.T) {
+	sensor := _newStaticMeasureSensor(412, 37)
+
+	var dst [2]uint16
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := sensor.MeasureInto(&dst); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Error("expected no allocations", allocs)
+	}
+}
+
+func TestMeasureIntoAllocationsWithDelays(t *testing.T) {
+	sensor := _newStaticMeasureSensor(412, 37)
+	sensor.cfg.Delay = DefaultDelay
+	sensor.cfg.CommandDelays = DefaultCommandDelays()
+
+	var dst [2]uint16
+	allocs := testing.AllocsPerRun(5, func() {
+		if err := sensor.MeasureInto(&dst); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Error("expected no allocations with the default delays", allocs)
+	}
+}
+
+func BenchmarkMeasureInto(b *testing.B) {
+	sensor := _newStaticMeasureSensor(412, 37)
+
+	var dst [2]uint16
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := sensor.MeasureInto(&dst); err != nil {
+			b.Fatal("unexpected error", err)
+		}
+	}
+}
+
+func BenchmarkMeasure(b *testing.B) {
+	sensor := _newStaticMeasureSensor(412, 37)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			b.Fatal("unexpected error", err)
+		}
+	}
+}
+
+func BenchmarkGetBaseline(b *testing.B) {
+	sensor := _newStaticMeasureSensor(0x8973, 0x8aae)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := sensor.GetBaseline(); err != nil {
+			b.Fatal("unexpected error", err)
+		}
+	}
+}
+
+func _newStaticMeasureSensor(eCO2 uint16, TVOC uint16) *SGP30Sensor {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+
+	reply := append(packWordCrc(eCO2), packWordCrc(TVOC)...)
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error { return nil },
+		readClosure: func(buf []byte) error {
+			copy(buf, reply)
+			return nil
+		},
+	}
+
+	return sensor
+}
+
+func TestMeasurementSerialID(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Name = "living-room"
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+		MeasureAirQuality:    {412, 3},
+	})
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	last, ok := sensor.LastMeasurement()
+	if !ok || last.SerialID != 0x010203040506 {
+		t.Errorf("expected measurement tagged with serial, %x", last.SerialID)
+	}
+
+	if last.SensorName != "living-room" {
+		t.Error("expected measurement tagged with sensor name", last.SensorName)
+	}
+
+	if history := sensor.Query(last.Timestamp, last.Timestamp); len(history) != 1 || history[0].SerialID != 0x010203040506 {
+		t.Error("expected history tagged with serial", history)
+	}
+}
+
+func TestMeasureReading(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	m, err := sensor.MeasureReading()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if m.ECO2 != 412 || m.TVOC != 35 || !m.Timestamp.Equal(clock.Now()) {
+		t.Error("unexpected measurement", m)
+	}
+}
+
+func TestMeasureAll(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	clock := _newFakeClock(sensor)
+	mock := &_mockI2cConnection{}
+	sensor.i2cConnection = mock
+
+	var written [][]byte
+	var reply []byte
+	mock.writeClosure = func(buf []byte) error {
+		written = append(written, buf)
+		if _bytesMatchUint(buf, MeasureAirQuality) {
+			reply = []byte{0x01, 0x9c, 0x31, 0x00, 0x23, 0x54}
+		} else if _bytesMatchUint(buf, MeasureRawSignals) {
+			reply = []byte{0x35, 0x20, 0xc2, 0x4b, 0x00, 0x12}
+		}
+
+		return nil
+	}
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, reply)
+		return nil
+	}
+
+	eCO2, TVOC, h2, ethanol, err := sensor.MeasureAll()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if eCO2 != 412 || TVOC != 35 || h2 != 13600 || ethanol != 19200 {
+		t.Error("unexpected values", eCO2, TVOC, h2, ethanol)
+	}
+
+	if len(written) != 2 || !_bytesMatchUint(written[0], MeasureAirQuality) || !_bytesMatchUint(written[1], MeasureRawSignals) {
+		t.Errorf("unexpected commands, %x", written)
+	}
+
+	expected := []time.Duration{12 * time.Millisecond, RawSignalsDelay}
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != expected[0] || clock.sleeps[1] != expected[1] {
+		t.Error("unexpected delays", clock.sleeps)
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, []byte{0x01, 0x9c, 0x00, 0x00, 0x23, 0x54})
+		return nil
+	}
+
+	if _, _, _, _, err := sensor.MeasureAll(); err == nil {
+		t.Error("expected crc error")
+	}
+}
+
+func TestMeasurementJSON(t *testing.T) {
+	m := Measurement{ECO2: 412, TVOC: 35, Timestamp: time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if string(data) != `{"eco2_ppm":412,"tvoc_ppb":35,"timestamp":"2020-01-01T12:30:00Z"}` {
+		t.Error("unexpected json", string(data))
+	}
+
+	m.SerialID = 0x010203040506
+	m.SensorName = "living-room"
+	m.InWarmup = true
+
+	if data, err = json.Marshal(m); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var decoded Measurement
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if decoded != m {
+		t.Error("unexpected round trip", m, decoded)
+	}
+}
+
+func TestValidateRanges(t *testing.T) {
+	table := []struct {
+		reading [2]uint16
+		valid   bool
+	}{
+		{[2]uint16{400, 0}, true},
+		{[2]uint16{412, 35}, true},
+		{[2]uint16{60000, 60000}, true},
+		{[2]uint16{399, 35}, false},
+		{[2]uint16{0, 0}, false},
+		{[2]uint16{60001, 35}, false},
+		{[2]uint16{412, 60001}, false},
+	}
+
+	for _, row := range table {
+		sensor := NewSensor(DefaultConfig())
+		_newFakeClock(sensor)
+		_mockMeasurements(sensor, [][2]uint16{row.reading})
+
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Error("expected no validation by default", row.reading, err)
+		}
+
+		sensor.cfg.ValidateRanges = true
+
+		eCO2, TVOC, err := sensor.Measure()
+		if row.valid {
+			if err != nil || eCO2 != row.reading[0] || TVOC != row.reading[1] {
+				t.Error("unexpected result", row.reading, eCO2, TVOC, err)
+			}
+		} else if !errors.Is(err, ErrValueOutOfRange) {
+			t.Error("expected out of range error", row.reading, err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package sensor
+
+import "time"
+
+// Metrics observes how long each bus transaction took. The prom package has a
+// Prometheus histogram implementation.
+type Metrics interface {
+	ObserveTransaction(cmd Command, d time.Duration)
+}
+
+// Stats counts bus errors since the sensor was created or the last
+// ResetCaches. Init and Reconnect leave them running.
+type Stats struct {
+	CRCErrors int
+	// ReadFailures counts writes and reads that failed on the bus.
+	ReadFailures int
+}
+
+func (s *SGP30Sensor) Stats() Stats {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	return Stats{CRCErrors: s.crcErrors, ReadFailures: s.readFailures}
+}
@@ -0,0 +1,72 @@
+package sensor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type _transactionObservation struct {
+	cmd Command
+	d   time.Duration
+}
+
+type _mockMetrics struct {
+	observations []_transactionObservation
+}
+
+func (m *_mockMetrics) ObserveTransaction(cmd Command, d time.Duration) {
+	m.observations = append(m.observations, _transactionObservation{cmd: cmd, d: d})
+}
+
+func TestMetricsObserveTransaction(t *testing.T) {
+	metrics := &_mockMetrics{}
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Metrics = metrics
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := _transactionObservation{cmd: MeasureAirQuality, d: 12 * time.Millisecond}
+	if len(metrics.observations) != 1 || metrics.observations[0] != expected {
+		t.Error("unexpected observations", metrics.observations)
+	}
+}
+
+func TestStats(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	_newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	readClosure := mock.readClosure
+	reads := 0
+	mock.readClosure = func(buf []byte) error {
+		reads++
+		switch reads {
+		case 1:
+			return errors.New("bus fault")
+		case 2:
+			copy(buf, []byte{0x01, 0x9c, 0x00, 0x00, 0x23, 0x54})
+			return nil
+		}
+
+		return readClosure(buf)
+	}
+
+	for i := 0; i < 3; i++ {
+		sensor.Measure()
+	}
+
+	if stats := sensor.Stats(); stats != (Stats{CRCErrors: 1, ReadFailures: 1}) {
+		t.Errorf("unexpected stats, %+v", stats)
+	}
+
+	sensor.ResetCaches()
+
+	if stats := sensor.Stats(); stats != (Stats{}) {
+		t.Errorf("expected stats cleared, %+v", stats)
+	}
+}
@@ -0,0 +1,91 @@
+// Package mqtt publishes SGP30 readings to an MQTT broker. The broker client
+// sits behind the Client interface, so any library can be adapted to it.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ataboo/sgp30go/sensor"
+)
+
+// Client is the part of an MQTT client the publisher needs. With paho, wrap
+// Connect and Publish to wait on their tokens.
+type Client interface {
+	Connect() error
+	IsConnected() bool
+	Publish(topic string, qos byte, payload []byte) error
+}
+
+// Publisher measures a sensor on a steady cadence and publishes each reading
+// to Topic as JSON.
+type Publisher struct {
+	Client Client
+	Topic  string
+	QoS    byte
+	// Interval defaults to sensor.DefaultMeasureInterval.
+	Interval time.Duration
+	Logger   sensor.Logger
+}
+
+// Run publishes readings from s until ctx is cancelled or the sensor is
+// closed. A reading that can't be published after reconnecting once is
+// dropped so the measurement cadence isn't held up by the broker.
+func (p *Publisher) Run(ctx context.Context, s *sensor.SGP30Sensor) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = sensor.DefaultMeasureInterval
+	}
+
+	measurements, errs := s.StartMeasuring(ctx, interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err, ok := <-errs:
+			if !ok {
+				return ctx.Err()
+			}
+			p.logError("measurement failed: %s", err)
+		case m, ok := <-measurements:
+			if !ok {
+				return ctx.Err()
+			}
+
+			if err := p.publish(m); err != nil {
+				p.logError("dropped reading: %s", err)
+			}
+		}
+	}
+}
+
+func (p *Publisher) publish(m sensor.Measurement) error {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if !p.Client.IsConnected() {
+		if err := p.Client.Connect(); err != nil {
+			return err
+		}
+	}
+
+	if err := p.Client.Publish(p.Topic, p.QoS, payload); err == nil {
+		return nil
+	}
+
+	if err := p.Client.Connect(); err != nil {
+		return err
+	}
+
+	return p.Client.Publish(p.Topic, p.QoS, payload)
+}
+
+func (p *Publisher) logError(msg string, params ...interface{}) {
+	if p.Logger != nil {
+		p.Logger.Errorf(msg, params...)
+	}
+}
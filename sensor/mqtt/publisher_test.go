@@ -0,0 +1,153 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ataboo/sgp30go/sensor"
+)
+
+type _mockConnection struct {
+	command uint16
+}
+
+func (c *_mockConnection) Read(buf []byte) error {
+	if c.command == uint16(sensor.MeasureAirQuality) {
+		copy(buf, []byte{0x01, 0x9c, 0x31, 0x00, 0x23, 0x54})
+	}
+
+	return nil
+}
+
+func (c *_mockConnection) ReadReg(reg byte, buf []byte) error {
+	return nil
+}
+
+func (c *_mockConnection) Write(buf []byte) error {
+	c.command = binary.BigEndian.Uint16(buf)
+	return nil
+}
+
+func (c *_mockConnection) WriteReg(reg byte, buf []byte) error {
+	return nil
+}
+
+func (c *_mockConnection) Close() error {
+	return nil
+}
+
+type _fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *_fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *_fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+type _publish struct {
+	topic   string
+	qos     byte
+	payload []byte
+}
+
+type _fakeClient struct {
+	connected bool
+	connects  int
+	failNext  bool
+	published chan _publish
+}
+
+func (c *_fakeClient) Connect() error {
+	c.connects++
+	c.connected = true
+	return nil
+}
+
+func (c *_fakeClient) IsConnected() bool {
+	return c.connected
+}
+
+func (c *_fakeClient) Publish(topic string, qos byte, payload []byte) error {
+	if c.failNext {
+		c.failNext = false
+		c.connected = false
+		return errors.New("broker went away")
+	}
+
+	c.published <- _publish{topic: topic, qos: qos, payload: payload}
+	return nil
+}
+
+func TestPublisherRun(t *testing.T) {
+	s := sensor.NewSensorWithOptions(
+		sensor.WithConnection(&_mockConnection{}),
+		sensor.WithClock(&_fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}),
+	)
+
+	client := &_fakeClient{failNext: true, published: make(chan _publish)}
+	publisher := &Publisher{Client: client, Topic: "home/air", QoS: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- publisher.Run(ctx, s)
+	}()
+
+	for i := 0; i < 3; i++ {
+		published := <-client.published
+		if published.topic != "home/air" || published.qos != 1 {
+			t.Error("unexpected publish", published.topic, published.qos)
+		}
+
+		var m sensor.Measurement
+		if err := json.Unmarshal(published.payload, &m); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if m.ECO2 != 412 || m.TVOC != 35 {
+			t.Error("unexpected payload", string(published.payload))
+		}
+
+		// Readings are stamped after the 12ms measure delay.
+		if expected := time.Date(2020, 1, 1, 0, 0, i, int(12*time.Millisecond), time.UTC); !m.Timestamp.Equal(expected) {
+			t.Error("expected a reading every second", expected, m.Timestamp)
+		}
+	}
+
+	cancel()
+
+	// A reading may already be on its way to the client.
+	timeout := time.After(time.Second)
+	for stopped := false; !stopped; {
+		select {
+		case <-client.published:
+		case err := <-done:
+			if err != context.Canceled {
+				t.Error("expected context error", err)
+			}
+			stopped = true
+		case <-timeout:
+			t.Fatal("expected Run to stop on cancel")
+		}
+	}
+
+	if client.connects != 2 {
+		t.Error("expected a connect and a reconnect after the failed publish", client.connects)
+	}
+}
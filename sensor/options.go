@@ -0,0 +1,105 @@
+package sensor
+
+import "time"
+
+// Option configures a sensor built with NewSensorWithOptions.
+type Option func(s *SGP30Sensor)
+
+// NewSensorWithOptions builds a sensor from DefaultConfig with opts applied
+// in order.
+func NewSensorWithOptions(opts ...Option) *SGP30Sensor {
+	s := &SGP30Sensor{
+		cfg:   DefaultConfig(),
+		clock: realClock{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// A caller that brings its own connection gets no default opener, so a
+	// reconnect can't close it and open /dev/i2c-* behind its back.
+	if s.opener == nil && s.i2cConnection == nil {
+		s.opener = devfsOpener{}
+	}
+
+	if s.cfg.Logger == nil {
+		s.cfg.Logger = NopLogger{}
+	}
+
+	if s.i2cConnection != nil {
+		s.i2cConnection = s.traced(s.i2cConnection)
+	}
+
+	return s
+}
+
+// WithConfig replaces the whole config with a copy of cfg, so it should come
+// before any option that changes a single field.
+func WithConfig(cfg *Config) Option {
+	return func(s *SGP30Sensor) {
+		s.cfg = cfg.clone()
+	}
+}
+
+func WithI2CPath(path string) Option {
+	return func(s *SGP30Sensor) {
+		s.cfg.I2CFsPath = path
+	}
+}
+
+func WithAddress(addr byte) Option {
+	return func(s *SGP30Sensor) {
+		s.cfg.I2CAddr = addr
+	}
+}
+
+// WithDelay sets the reply delay for commands not in CommandDelays.
+func WithDelay(delay time.Duration) Option {
+	return func(s *SGP30Sensor) {
+		s.cfg.Delay = delay
+	}
+}
+
+// WithCommandDelay sets how long to wait for the reply to cmd.
+func WithCommandDelay(cmd Command, delay time.Duration) Option {
+	return func(s *SGP30Sensor) {
+		delays := make(map[Command]time.Duration, len(s.cfg.CommandDelays)+1)
+		for c, d := range s.cfg.CommandDelays {
+			delays[c] = d
+		}
+		delays[cmd] = delay
+
+		s.cfg.CommandDelays = delays
+	}
+}
+
+func WithLogger(logger Logger) Option {
+	return func(s *SGP30Sensor) {
+		s.cfg.Logger = logger
+	}
+}
+
+// WithClock replaces the wall clock used for delays and timestamps, mainly so
+// tests can run warm-up and cadence checks without real sleeps.
+func WithClock(clock Clock) Option {
+	return func(s *SGP30Sensor) {
+		s.clock = clock
+	}
+}
+
+// WithConnection uses conn instead of opening I2CFsPath on Init. Pair it with
+// WithOpener for Reconnect and AutoReconnect to work.
+func WithConnection(conn Connection) Option {
+	return func(s *SGP30Sensor) {
+		s.i2cConnection = conn
+	}
+}
+
+// WithOpener replaces how connections are opened on Init, Reconnect and
+// SoftReset.
+func WithOpener(opener Opener) Option {
+	return func(s *SGP30Sensor) {
+		s.opener = opener
+	}
+}
@@ -0,0 +1,113 @@
+package sensor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+func TestNewSensorWithOptions(t *testing.T) {
+	logger := logging.MustGetLogger("sgp30-test")
+	mock := &_mockI2cConnection{}
+
+	sensor := NewSensorWithOptions(
+		WithI2CPath("/dev/i2c-3"),
+		WithAddress(0x59),
+		WithDelay(25*time.Millisecond),
+		WithLogger(logger),
+		WithConnection(mock),
+	)
+
+	if sensor.cfg.I2CFsPath != "/dev/i2c-3" || sensor.cfg.I2CAddr != 0x59 || sensor.cfg.Delay != 25*time.Millisecond {
+		t.Errorf("unexpected config, %+v", sensor.cfg)
+	}
+
+	if sensor.cfg.Logger != logger || sensor.i2cConnection != mock {
+		t.Error("expected logger and connection to be set")
+	}
+
+	if sensor.cfg.MeasureInterval != DefaultMeasureInterval || sensor.cfg.HistorySize != DefaultHistorySize {
+		t.Errorf("expected options layered on the defaults, %+v", sensor.cfg)
+	}
+
+	if _, ok := sensor.clock.(realClock); !ok {
+		t.Error("expected the real clock")
+	}
+}
+
+func TestNewSensorUsesConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.I2CAddr = 0x60
+
+	sensor := NewSensor(cfg)
+	if sensor.cfg.I2CAddr != 0x60 {
+		t.Error("expected NewSensor to use the given config", sensor.cfg.I2CAddr)
+	}
+
+	cfg.I2CAddr = 0x61
+	if sensor.cfg == cfg || sensor.cfg.I2CAddr != 0x60 {
+		t.Error("expected NewSensor to copy the given config")
+	}
+
+	if _, ok := sensor.opener.(devfsOpener); !ok {
+		t.Error("expected the devfs opener")
+	}
+}
+
+func TestWithCommandDelay(t *testing.T) {
+	sensor := NewSensorWithOptions(
+		WithDelay(50*time.Millisecond),
+		WithCommandDelay(MeasureAirQuality, 5*time.Millisecond),
+	)
+	clock := _newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, []byte{0xd4, 0x00, 0xc6})
+		return nil
+	}
+
+	if _, err := sensor.SelfTest(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := []time.Duration{5 * time.Millisecond, 220 * time.Millisecond}
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != expected[0] || clock.sleeps[1] != expected[1] {
+		t.Error("unexpected delays", clock.sleeps)
+	}
+
+	if DefaultConfig().CommandDelays[MeasureAirQuality] != 12*time.Millisecond {
+		t.Error("expected WithCommandDelay to leave the defaults alone")
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	clock := &_fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	sensor := NewSensorWithOptions(WithClock(clock))
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+	sensor.startWarmup()
+
+	m, err := sensor.MeasureReading()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !m.Timestamp.Equal(clock.now) || !m.InWarmup {
+		t.Error("expected the injected clock", m)
+	}
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 12*time.Millisecond {
+		t.Error("expected the delay to go through the injected clock", clock.sleeps)
+	}
+
+	clock.Advance(WarmupDuration)
+
+	if !sensor.IsWarmedUp() {
+		t.Error("expected warm-up to follow the injected clock")
+	}
+}
@@ -0,0 +1,37 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"time"
+)
+
+const (
+	PollBackoff     = time.Millisecond
+	PollMaxAttempts = 50
+)
+
+// errNAK is EREMOTEIO, which i2c-dev returns on Linux when the device does
+// not acknowledge its address.
+var errNAK = syscall.Errno(0x79)
+
+func isNAK(err error) bool {
+	return errors.Is(err, errNAK)
+}
+
+// pollRead reads as soon as the device acknowledges rather than waiting out a
+// fixed delay. The SGP30 NAKs read requests until the measurement is done.
+func (s *SGP30Sensor) pollRead(ctx context.Context, buf []byte) (err error) {
+	for attempt := 0; attempt < PollMaxAttempts; attempt++ {
+		if err = s.i2cConnection.Read(buf); !isNAK(err) {
+			return err
+		}
+
+		if err := s.sleepContext(ctx, PollBackoff); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,72 @@
+package sensor
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestPollForReady(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PollForReady = true
+	sensor := NewSensor(cfg)
+	clock := _newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{450, 12}})
+
+	readClosure := mock.readClosure
+	naks := 0
+	mock.readClosure = func(buf []byte) error {
+		if naks < 2 {
+			naks++
+			return &os.PathError{Op: "read", Path: DefaultI2CFsPath, Err: errNAK}
+		}
+
+		return readClosure(buf)
+	}
+
+	eCO2, TVOC, err := sensor.Measure()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if eCO2 != 450 || TVOC != 12 {
+		t.Error("unexpected values", eCO2, TVOC)
+	}
+
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != PollBackoff || clock.sleeps[1] != PollBackoff {
+		t.Error("expected poll backoff instead of fixed delay", clock.sleeps)
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		return fmt.Errorf("bus error")
+	}
+
+	clock.sleeps = nil
+	if _, _, err := sensor.Measure(); err == nil {
+		t.Error("expected error")
+	}
+
+	if len(clock.sleeps) != 0 {
+		t.Error("expected no retries for non-NAK errors", clock.sleeps)
+	}
+}
+
+func TestPollForReadyGivesUp(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PollForReady = true
+	sensor := NewSensor(cfg)
+	clock := _newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{450, 12}})
+
+	mock.readClosure = func(buf []byte) error {
+		return errNAK
+	}
+
+	if _, _, err := sensor.Measure(); !isNAK(err) {
+		t.Error("expected NAK error", err)
+	}
+
+	if len(clock.sleeps) != PollMaxAttempts {
+		t.Error("unexpected attempt count", PollMaxAttempts, len(clock.sleeps))
+	}
+}
@@ -0,0 +1,71 @@
+// Package prom exposes SGP30 readings, bus error counts and transaction
+// durations as Prometheus metrics.
+package prom
+
+import (
+	"fmt"
+
+	"github.com/ataboo/sgp30go/sensor"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements prometheus.Collector for one sensor, labelled with its
+// serial and, when set, its Config.Name. By default a scrape reports the last
+// cached reading, so something else such as StartMeasuring must keep the
+// sensor measuring at 1Hz.
+type Collector struct {
+	// MeasureOnScrape measures on every scrape instead of reading the cache.
+	// Scrapes rarely line up with the 1Hz cadence the baseline needs, so
+	// this is best left for sensors that aren't otherwise polled.
+	MeasureOnScrape bool
+
+	sensor       *sensor.SGP30Sensor
+	eco2         *prometheus.Desc
+	tvoc         *prometheus.Desc
+	crcErrors    *prometheus.Desc
+	readFailures *prometheus.Desc
+}
+
+// NewCollector builds a collector for s, which should already be
+// initialized so its serial is known.
+func NewCollector(s *sensor.SGP30Sensor) *Collector {
+	labels := prometheus.Labels{"serial": fmt.Sprintf("%012x", s.SerialID)}
+	if name := s.Name(); name != "" {
+		labels["name"] = name
+	}
+
+	return &Collector{
+		sensor:       s,
+		eco2:         prometheus.NewDesc("sgp30_eco2_ppm", "Equivalent CO2 in ppm.", nil, labels),
+		tvoc:         prometheus.NewDesc("sgp30_tvoc_ppb", "Total volatile organic compounds in ppb.", nil, labels),
+		crcErrors:    prometheus.NewDesc("sgp30_crc_errors_total", "Replies that failed their checksum.", nil, labels),
+		readFailures: prometheus.NewDesc("sgp30_read_failures_total", "Writes and reads that failed on the bus.", nil, labels),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.eco2
+	ch <- c.tvoc
+	ch <- c.crcErrors
+	ch <- c.readFailures
+}
+
+// Collect reports the reading and error counters. The gauges are left out
+// when there is no reading yet or measuring fails.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	m, ok := c.sensor.LastMeasurement()
+	if c.MeasureOnScrape {
+		var err error
+		m, err = c.sensor.MeasureReading()
+		ok = err == nil
+	}
+
+	if ok {
+		ch <- prometheus.MustNewConstMetric(c.eco2, prometheus.GaugeValue, float64(m.ECO2))
+		ch <- prometheus.MustNewConstMetric(c.tvoc, prometheus.GaugeValue, float64(m.TVOC))
+	}
+
+	stats := c.sensor.Stats()
+	ch <- prometheus.MustNewConstMetric(c.crcErrors, prometheus.CounterValue, float64(stats.CRCErrors))
+	ch <- prometheus.MustNewConstMetric(c.readFailures, prometheus.CounterValue, float64(stats.ReadFailures))
+}
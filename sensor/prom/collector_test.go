@@ -0,0 +1,119 @@
+package prom
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/ataboo/sgp30go/sensor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type _mockConnection struct {
+	command uint16
+}
+
+func (c *_mockConnection) Read(buf []byte) error {
+	if c.command == uint16(sensor.MeasureAirQuality) {
+		copy(buf, []byte{0x01, 0x9c, 0x31, 0x00, 0x23, 0x54})
+	}
+
+	return nil
+}
+
+func (c *_mockConnection) ReadReg(reg byte, buf []byte) error {
+	return nil
+}
+
+func (c *_mockConnection) Write(buf []byte) error {
+	c.command = binary.BigEndian.Uint16(buf)
+	return nil
+}
+
+func (c *_mockConnection) WriteReg(reg byte, buf []byte) error {
+	return nil
+}
+
+func (c *_mockConnection) Close() error {
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	s := sensor.NewSensorWithConnection(sensor.DefaultConfig(), &_mockConnection{})
+	s.SerialID = 0x010203040506
+
+	collector := NewCollector(s)
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if count := testutil.CollectAndCount(collector); count != 2 {
+		t.Error("expected only the counters before a reading", count)
+	}
+
+	if _, _, err := s.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if count := testutil.CollectAndCount(collector); count != 4 {
+		t.Error("expected gauges after a reading", count)
+	}
+
+	expected := `
+# HELP sgp30_eco2_ppm Equivalent CO2 in ppm.
+# TYPE sgp30_eco2_ppm gauge
+sgp30_eco2_ppm{serial="010203040506"} 412
+# HELP sgp30_tvoc_ppb Total volatile organic compounds in ppb.
+# TYPE sgp30_tvoc_ppb gauge
+sgp30_tvoc_ppb{serial="010203040506"} 35
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "sgp30_eco2_ppm", "sgp30_tvoc_ppb"); err != nil {
+		t.Error("unexpected metrics", err)
+	}
+}
+
+func TestCollectorMeasureOnScrape(t *testing.T) {
+	s := sensor.NewSensorWithConnection(sensor.DefaultConfig(), &_mockConnection{})
+
+	collector := NewCollector(s)
+	collector.MeasureOnScrape = true
+
+	if count := testutil.CollectAndCount(collector); count != 4 {
+		t.Error("expected a reading from the scrape", count)
+	}
+
+	if _, ok := s.LastMeasurement(); !ok {
+		t.Error("expected the scrape to measure")
+	}
+}
+
+func TestCollectorNameLabel(t *testing.T) {
+	cfg := sensor.DefaultConfig()
+	cfg.Name = "living-room"
+	s := sensor.NewSensorWithConnection(cfg, &_mockConnection{})
+	s.SerialID = 0x010203040506
+
+	collector := NewCollector(s)
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, _, err := s.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := `
+# HELP sgp30_eco2_ppm Equivalent CO2 in ppm.
+# TYPE sgp30_eco2_ppm gauge
+sgp30_eco2_ppm{name="living-room",serial="010203040506"} 412
+# HELP sgp30_crc_errors_total Replies that failed their checksum.
+# TYPE sgp30_crc_errors_total counter
+sgp30_crc_errors_total{name="living-room",serial="010203040506"} 0
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "sgp30_eco2_ppm", "sgp30_crc_errors_total"); err != nil {
+		t.Error("unexpected metrics", err)
+	}
+}
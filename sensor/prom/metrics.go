@@ -0,0 +1,39 @@
+package prom
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ataboo/sgp30go/sensor"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TransactionMetrics implements sensor.Metrics as a histogram of bus
+// transaction durations labelled by command. Set it as Config.Metrics and
+// register it like any other collector.
+type TransactionMetrics struct {
+	durations *prometheus.HistogramVec
+}
+
+func NewTransactionMetrics() *TransactionMetrics {
+	return &TransactionMetrics{
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "sgp30_transaction_duration_seconds",
+			Help: "Time from writing a command to decoding its reply.",
+			// 1ms up to about half a second, covering the 220ms self-test.
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 10),
+		}, []string{"command"}),
+	}
+}
+
+func (m *TransactionMetrics) ObserveTransaction(cmd sensor.Command, d time.Duration) {
+	m.durations.WithLabelValues(fmt.Sprintf("0x%04x", uint16(cmd))).Observe(d.Seconds())
+}
+
+func (m *TransactionMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.durations.Describe(ch)
+}
+
+func (m *TransactionMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.durations.Collect(ch)
+}
@@ -0,0 +1,89 @@
+package prom
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ataboo/sgp30go/sensor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransactionMetrics(t *testing.T) {
+	metrics := NewTransactionMetrics()
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(metrics); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	metrics.ObserveTransaction(sensor.MeasureAirQuality, 12*time.Millisecond)
+	metrics.ObserveTransaction(sensor.MeasureAirQuality, 3*time.Millisecond)
+	metrics.ObserveTransaction(sensor.MeasureTest, 220*time.Millisecond)
+
+	expected := `
+# HELP sgp30_transaction_duration_seconds Time from writing a command to decoding its reply.
+# TYPE sgp30_transaction_duration_seconds histogram
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.001"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.002"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.004"} 1
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.008"} 1
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.016"} 2
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.032"} 2
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.064"} 2
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.128"} 2
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.256"} 2
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="0.512"} 2
+sgp30_transaction_duration_seconds_bucket{command="0x2008",le="+Inf"} 2
+sgp30_transaction_duration_seconds_sum{command="0x2008"} 0.015
+sgp30_transaction_duration_seconds_count{command="0x2008"} 2
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.001"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.002"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.004"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.008"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.016"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.032"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.064"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.128"} 0
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.256"} 1
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="0.512"} 1
+sgp30_transaction_duration_seconds_bucket{command="0x2032",le="+Inf"} 1
+sgp30_transaction_duration_seconds_sum{command="0x2032"} 0.22
+sgp30_transaction_duration_seconds_count{command="0x2032"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected)); err != nil {
+		t.Error("unexpected metrics", err)
+	}
+}
+
+func TestTransactionMetricsFromSensor(t *testing.T) {
+	metrics := NewTransactionMetrics()
+	cfg := sensor.DefaultConfig()
+	cfg.Metrics = metrics
+	cfg.CommandDelays = nil
+	s := sensor.NewSensorWithConnection(cfg, &_mockConnection{})
+
+	if _, _, err := s.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if count := testutil.CollectAndCount(metrics); count != 1 {
+		t.Error("expected one series for the measure command", count)
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(metrics)
+	families, err := registry.Gather()
+	if err != nil || len(families) != 1 {
+		t.Fatal("unexpected gather", families, err)
+	}
+
+	series := families[0].GetMetric()[0]
+	if label := series.GetLabel()[0]; label.GetName() != "command" || label.GetValue() != "0x2008" {
+		t.Error("unexpected label", label)
+	}
+
+	if count := series.GetHistogram().GetSampleCount(); count != 1 {
+		t.Error("expected one observation", count)
+	}
+}
@@ -0,0 +1,107 @@
+package sensor
+
+import "time"
+
+const (
+	// ErrorRateWindow is how many recent transactions NeedsReset considers.
+	ErrorRateWindow = 10
+
+	GeneralCallAddr  byte = 0x00
+	GeneralCallReset byte = 0x06
+	SoftResetDelay        = time.Millisecond
+)
+
+// ResetCaches clears history, baseline history, cached measurements, cadence
+// tracking and error counters without touching the connection, serial or
+// feature set.
+func (s *SGP30Sensor) ResetCaches() {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.crcErrors = 0
+	s.readFailures = 0
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.history = measurementRing{}
+	s.baselineHistory = nil
+	s.lastRaw = Measurement{}
+	s.last = Measurement{}
+	s.lastMeasure = time.Time{}
+	s.lastInterval = 0
+	s.smoothing = movingAverage{}
+	s.measureErrors = 0
+	s.outcomes = [ErrorRateWindow]bool{}
+	s.outcomeCount = 0
+}
+
+// NeedsReset reports whether more than ResetErrorRate of the last
+// ErrorRateWindow transactions failed, which usually means the sensor is
+// wedged and wants a soft reset rather than a reconnect.
+func (s *SGP30Sensor) NeedsReset() bool {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	maxRate := s.config().ResetErrorRate
+	if maxRate <= 0 || s.outcomeCount < ErrorRateWindow {
+		return false
+	}
+
+	failures := 0
+	for _, failed := range s.outcomes {
+		if failed {
+			failures++
+		}
+	}
+
+	return float64(failures)/ErrorRateWindow > maxRate
+}
+
+func (s *SGP30Sensor) recordOutcome(err error) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.outcomes[s.outcomeCount%ErrorRateWindow] = err != nil
+	s.outcomeCount++
+}
+
+// SoftReset sends the I2C general call reset and waits for the sensor to come
+// back up. The general call goes to address 0x00, so EVERY device on the bus
+// that honours it resets, not just this sensor. The air quality algorithm
+// restarts too, so follow it with WarmReset before measuring.
+func (s *SGP30Sensor) SoftReset() error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	if s.opener == nil {
+		return ErrNoOpener
+	}
+
+	if err := s.generalCall([]byte{GeneralCallReset}); err != nil {
+		return mapBusError(err)
+	}
+
+	s.clock.Sleep(SoftResetDelay)
+
+	return nil
+}
+
+func (s *SGP30Sensor) generalCall(data []byte) error {
+	path := s.config().I2CFsPath
+	if caller, ok := s.opener.(GeneralCaller); ok {
+		return caller.GeneralCall(path, data)
+	}
+
+	conn, err := s.opener.Open(path, GeneralCallAddr)
+	if err != nil {
+		return err
+	}
+
+	err = conn.Write(data)
+	if closeErr := conn.Close(); closeErr != nil {
+		s.logError("failed closing general call connection: %s", closeErr)
+	}
+
+	return err
+}
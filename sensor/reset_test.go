@@ -0,0 +1,208 @@
+package sensor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestResetCaches(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.MinMeasureInterval = time.Second
+	sensor.SerialID = 0x010203040506
+	sensor.featureSet = FeatureSet{ProductType: ProductTypeSGP30, ProductVersion: 0x20}
+	clock := _newFakeClock(sensor)
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		MeasureAirQuality: {412, 3},
+		GetBaseline:       {0x8a00, 0x8b00},
+	})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, _, err := sensor.GetBaseline(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	sensor.crcErrors = 2
+
+	if _, _, err := sensor.Measure(); err != ErrMeasureTooSoon {
+		t.Fatal("expected cadence check before reset", err)
+	}
+
+	sensor.ResetCaches()
+
+	if _, ok := sensor.LastMeasurement(); ok {
+		t.Error("expected no cached measurement")
+	}
+
+	if _, ok := sensor.LastRawMeasurement(); ok {
+		t.Error("expected no cached raw measurement")
+	}
+
+	if history := sensor.Query(time.Time{}, clock.Now()); len(history) != 0 {
+		t.Error("expected empty history", history)
+	}
+
+	if len(sensor.baselineHistory) != 0 || sensor.crcErrors != 0 {
+		t.Error("expected baseline history and stats cleared", sensor.baselineHistory, sensor.crcErrors)
+	}
+
+	if sensor.i2cConnection != mock {
+		t.Error("expected connection to remain")
+	}
+
+	if sensor.SerialID != 0x010203040506 || sensor.featureSet.ProductVersion != 0x20 {
+		t.Error("expected serial and feature set to remain", sensor.SerialID, sensor.featureSet)
+	}
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Error("expected cadence tracking cleared", err)
+	}
+}
+
+func TestNeedsReset(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 3}})
+	writeClosure := mock.writeClosure
+
+	for i := 0; i < ErrorRateWindow; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	if sensor.NeedsReset() {
+		t.Error("expected no reset while healthy")
+	}
+
+	mock.writeClosure = func(buf []byte) error {
+		return fmt.Errorf("bus error")
+	}
+
+	for i := 0; i < ErrorRateWindow/2; i++ {
+		sensor.Measure()
+	}
+
+	if sensor.NeedsReset() {
+		t.Error("expected no reset at the threshold")
+	}
+
+	sensor.Measure()
+
+	if !sensor.NeedsReset() {
+		t.Error("expected reset after an error burst")
+	}
+
+	mock.writeClosure = writeClosure
+
+	for i := 0; i < ErrorRateWindow/2; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	if sensor.NeedsReset() {
+		t.Error("expected no reset after recovery")
+	}
+}
+
+func TestSoftReset(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	clock := _newFakeClock(sensor)
+	sensorConn := _mockMeasurements(sensor, [][2]uint16{{412, 3}})
+
+	var written []byte
+	closed := false
+	sensor.opener = &_mockOpener{
+		openClosure: func(path string, addr byte) (Connection, error) {
+			if path != DefaultI2CFsPath || addr != GeneralCallAddr {
+				t.Error("unexpected general call target", path, addr)
+			}
+
+			return &_mockI2cConnection{
+				writeClosure: func(buf []byte) error {
+					written = append([]byte(nil), buf...)
+					return nil
+				},
+				closeClosure: func() error {
+					closed = true
+					return nil
+				},
+			}, nil
+		},
+	}
+
+	if err := sensor.SoftReset(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !_bytesMatch(written, []byte{0x06}) {
+		t.Error("unexpected reset write", written)
+	}
+
+	if !closed {
+		t.Error("expected general call connection to be closed")
+	}
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != SoftResetDelay {
+		t.Error("expected the reset delay", clock.sleeps)
+	}
+
+	if sensor.i2cConnection != sensorConn {
+		t.Error("expected the sensor connection to be kept")
+	}
+
+	sensor.opener = &_mockOpener{
+		openClosure: func(path string, addr byte) (Connection, error) {
+			return nil, fmt.Errorf("open failed")
+		},
+	}
+
+	if err := sensor.SoftReset(); err == nil {
+		t.Error("expected open error")
+	}
+}
+
+type _generalCallOpener struct {
+	_mockOpener
+	generalCallClosure func(path string, data []byte) error
+}
+
+func (o *_generalCallOpener) GeneralCall(path string, data []byte) error {
+	return o.generalCallClosure(path, data)
+}
+
+func TestSoftResetGeneralCaller(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	clock := _newFakeClock(sensor)
+
+	var written []byte
+	sensor.opener = &_generalCallOpener{
+		_mockOpener: _mockOpener{
+			openClosure: func(path string, addr byte) (Connection, error) {
+				t.Error("expected GeneralCall instead of Open", addr)
+				return nil, fmt.Errorf("open failed")
+			},
+		},
+		generalCallClosure: func(path string, data []byte) error {
+			if path != DefaultI2CFsPath {
+				t.Error("unexpected bus", path)
+			}
+
+			written = append([]byte(nil), data...)
+			return nil
+		},
+	}
+
+	if err := sensor.SoftReset(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !_bytesMatch(written, []byte{GeneralCallReset}) || len(clock.sleeps) != 1 {
+		t.Error("expected the reset through GeneralCall", written, clock.sleeps)
+	}
+}
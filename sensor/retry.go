@@ -0,0 +1,105 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// transactRetry retries transient bus errors up to Retries times, then with
+// AutoReconnect reopens the connection once if the bus itself failed and the
+// sensor has an Opener. The caller holds the transaction lock, so a retried
+// command is never interleaved.
+func (s *SGP30Sensor) transactRetry(ctx context.Context, command []byte, dst []uint16, fallbackDelay time.Duration) error {
+	err := s.retryTransient(ctx, command, dst, fallbackDelay)
+	if err == nil || !s.config().AutoReconnect || s.opener == nil || ctx.Err() != nil || !isBusFailure(err) {
+		return err
+	}
+
+	s.logError("transaction failed, reconnecting: %s", err)
+	if reconnectErr := s.reconnect(ctx); reconnectErr != nil {
+		s.logError("reconnect failed: %s", reconnectErr)
+		return err
+	}
+
+	err = s.transact(ctx, command, dst, s.commandDelay(command, 0, fallbackDelay))
+	if ctx.Err() == nil {
+		s.recordOutcome(err)
+	}
+
+	return err
+}
+
+func (s *SGP30Sensor) retryTransient(ctx context.Context, command []byte, dst []uint16, fallbackDelay time.Duration) error {
+	cfg := s.config()
+	for attempt := 0; ; attempt++ {
+		err := s.transact(ctx, command, dst, s.commandDelay(command, attempt, fallbackDelay))
+		if ctx.Err() == nil {
+			s.recordOutcome(err)
+		}
+
+		if err == nil || attempt >= cfg.Retries || !isTransient(err) {
+			return err
+		}
+
+		s.logDebug("retrying command %x after transient error: %s", command, err)
+
+		if err := s.sleepContext(ctx, cfg.RetryDelay); err != nil {
+			return err
+		}
+	}
+}
+
+// readError marks a failed read after a successful write. Reads are safe to
+// retry since the retry writes the command again.
+type readError struct {
+	err error
+}
+
+func (e *readError) Error() string {
+	return e.err.Error()
+}
+
+func (e *readError) Unwrap() error {
+	return e.err
+}
+
+// writeError marks a failed command write.
+type writeError struct {
+	err error
+}
+
+func (e *writeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *writeError) Unwrap() error {
+	return e.err
+}
+
+// isTransient reports whether a retry could succeed: arbitration loss, a
+// corrupted reply or a failed read.
+func isTransient(err error) bool {
+	var readErr *readError
+	return errors.Is(err, ErrArbitrationLost) || IsCRCError(err) || errors.As(err, &readErr)
+}
+
+// isBusFailure reports whether the write or read itself failed, as opposed to
+// a reply that arrived corrupted.
+func isBusFailure(err error) bool {
+	var readErr *readError
+	var writeErr *writeError
+	return errors.As(err, &readErr) || errors.As(err, &writeErr)
+}
+
+// mapBusError types the errno i2c-dev returns when another master wins
+// arbitration, EAGAIN on Linux.
+func mapBusError(err error) error {
+	if errors.Is(err, syscall.EAGAIN) {
+		return fmt.Errorf("%w: %s", ErrArbitrationLost, err)
+	}
+
+	return err
+}
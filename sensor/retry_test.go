@@ -0,0 +1,143 @@
+package sensor
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestArbitrationLostRetried(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.Retries = 1
+	_newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	writeClosure := mock.writeClosure
+	lost := 0
+	mock.writeClosure = func(buf []byte) error {
+		if lost < 1 {
+			lost++
+			return &os.PathError{Op: "write", Path: DefaultI2CFsPath, Err: syscall.EAGAIN}
+		}
+
+		return writeClosure(buf)
+	}
+
+	eCO2, TVOC, err := sensor.Measure()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if eCO2 != 412 || TVOC != 35 {
+		t.Error("unexpected values", eCO2, TVOC)
+	}
+
+	lost = 0
+	sensor.cfg.Retries = 0
+
+	if _, _, err := sensor.Measure(); !errors.Is(err, ErrArbitrationLost) {
+		t.Error("expected arbitration lost error", err)
+	}
+}
+
+func TestDelayStrategy(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Retries = 2
+	clock := _newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	sensor.cfg.DelayStrategy = func(cmd Command, attempt int) time.Duration {
+		if cmd != MeasureAirQuality {
+			t.Error("unexpected command", cmd)
+		}
+
+		return time.Duration(attempt+1) * 10 * time.Millisecond
+	}
+
+	readClosure := mock.readClosure
+	lost := 0
+	mock.readClosure = func(buf []byte) error {
+		if lost < 2 {
+			lost++
+			return &os.PathError{Op: "read", Path: DefaultI2CFsPath, Err: syscall.EAGAIN}
+		}
+
+		return readClosure(buf)
+	}
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var delays []time.Duration
+	for _, d := range clock.sleeps {
+		if d != sensor.cfg.RetryDelay {
+			delays = append(delays, d)
+		}
+	}
+
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	if len(delays) != len(expected) {
+		t.Fatal("unexpected delays", delays)
+	}
+
+	for i := range expected {
+		if delays[i] != expected[i] {
+			t.Error("unexpected delay", i, expected[i], delays[i])
+		}
+	}
+}
+
+func TestTransientReadErrorsRetried(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.Retries = 2
+	sensor.cfg.RetryDelay = 5 * time.Millisecond
+	clock := _newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	readClosure := mock.readClosure
+	failures := 0
+	mock.readClosure = func(buf []byte) error {
+		failures++
+		switch failures {
+		case 1:
+			return &os.PathError{Op: "read", Path: DefaultI2CFsPath, Err: syscall.EIO}
+		case 2:
+			copy(buf, []byte{0x01, 0x9c, 0x00, 0x00, 0x23, 0x54})
+			return nil
+		}
+
+		return readClosure(buf)
+	}
+
+	eCO2, TVOC, err := sensor.Measure()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if eCO2 != 412 || TVOC != 35 {
+		t.Error("unexpected values", eCO2, TVOC)
+	}
+
+	retryDelays := 0
+	for _, d := range clock.sleeps {
+		if d == sensor.cfg.RetryDelay {
+			retryDelays++
+		}
+	}
+
+	if retryDelays != 2 {
+		t.Error("expected a delay before each retry", clock.sleeps)
+	}
+
+	failures = 0
+	sensor.cfg.Retries = 0
+
+	if _, _, err := sensor.Measure(); !errors.Is(err, syscall.EIO) {
+		t.Error("expected the read error without retries", err)
+	}
+}
@@ -0,0 +1,83 @@
+package sensor
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	SelfTestPattern uint16        = 0xD400
+	SelfTestDelay   time.Duration = 220 * time.Millisecond
+)
+
+type FactoryReport struct {
+	Serial               uint64
+	FeatureSet           FeatureSet
+	SelfTestPassed       bool
+	FirstMeasurement     Measurement
+	CRCErrorsEncountered int
+	Pass                 bool
+}
+
+func (s *SGP30Sensor) SelfTest() (bool, error) {
+	passed, _, err := s.SelfTestTimed()
+
+	return passed, err
+}
+
+// SelfTestTimed runs SelfTest and also returns how long it took, which should
+// be close to SelfTestDelay on a healthy unit.
+func (s *SGP30Sensor) SelfTestTimed() (passed bool, elapsed time.Duration, err error) {
+	start := s.clock.Now()
+	vals, err := s.readWordsDelay(context.Background(), commandFrame(MeasureTest), 1, SelfTestDelay)
+	elapsed = s.clock.Now().Sub(start)
+	if err != nil {
+		return false, elapsed, err
+	}
+
+	return vals[0] == SelfTestPattern, elapsed, nil
+}
+
+// FactoryTest runs the end-of-line checks on a fresh, uninitialized unit. The
+// self-test must run before InitAirQuality, so this replaces Init.
+func (s *SGP30Sensor) FactoryTest() (report FactoryReport, err error) {
+	if err := s.startI2CConnection(context.Background()); err != nil {
+		s.logError("%s", err)
+		return report, err
+	}
+
+	crcErrors := s.Stats().CRCErrors
+	defer func() {
+		report.CRCErrorsEncountered = s.Stats().CRCErrors - crcErrors
+	}()
+
+	serial, err := s.getSerial(context.Background())
+	if err != nil {
+		return report, err
+	}
+	s.SerialID = serial
+	report.Serial = serial
+
+	featureSet, err := s.getFeatureSet(context.Background())
+	if err != nil {
+		return report, err
+	}
+	report.FeatureSet = parseFeatureSet(featureSet)
+
+	if report.SelfTestPassed, err = s.SelfTest(); err != nil {
+		return report, err
+	}
+
+	if _, err := s.readWordsUint(InitAirQuality, 0); err != nil {
+		return report, err
+	}
+	s.startWarmup()
+
+	if report.FirstMeasurement, err = s.measure(context.Background()); err != nil {
+		return report, err
+	}
+
+	report.Pass = report.SelfTestPassed && report.FirstMeasurement.Plausible() && s.Stats().CRCErrors == crcErrors
+
+	return report, nil
+}
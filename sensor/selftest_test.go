@@ -0,0 +1,137 @@
+package sensor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFactoryTest(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+
+	responses := map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+		MeasureTest:          {SelfTestPattern},
+		MeasureAirQuality:    {400, 0},
+	}
+	_mockResponses(sensor, responses)
+
+	report, err := sensor.FactoryTest()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	expected := FactoryReport{
+		Serial:           0x010203040506,
+		FeatureSet:       FeatureSet{ProductType: 0, ProductVersion: 0x20},
+		SelfTestPassed:   true,
+		FirstMeasurement: Measurement{ECO2: 400, TVOC: 0, Timestamp: report.FirstMeasurement.Timestamp, SerialID: 0x010203040506, InWarmup: true},
+		Pass:             true,
+	}
+
+	if report != expected {
+		t.Errorf("unexpected report, %+v, %+v", expected, report)
+	}
+
+	responses[MeasureTest] = []uint16{0x1234}
+
+	report, err = sensor.FactoryTest()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if report.SelfTestPassed || report.Pass {
+		t.Errorf("expected failing report, %+v", report)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	table := []struct {
+		reply    []byte
+		expected bool
+	}{
+		{[]byte{0xd4, 0x00, 0xc6}, true},
+		{[]byte{0x4b, 0x00, 0x12}, false},
+	}
+
+	for _, row := range table {
+		sensor := NewSensor(DefaultConfig())
+		clock := _newFakeClock(sensor)
+		reply := row.reply
+		sensor.i2cConnection = &_mockI2cConnection{
+			writeClosure: func(buf []byte) error {
+				if !_bytesMatchUint(buf, MeasureTest) {
+					t.Error("unexpected command", buf)
+				}
+
+				return nil
+			},
+			readClosure: func(buf []byte) error {
+				copy(buf, reply)
+				return nil
+			},
+		}
+
+		passed, err := sensor.SelfTest()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if passed != row.expected {
+			t.Errorf("unexpected self test result for %x, %t", reply, passed)
+		}
+
+		if len(clock.sleeps) != 1 || clock.sleeps[0] != SelfTestDelay {
+			t.Error("expected the self test delay", clock.sleeps)
+		}
+	}
+}
+
+func TestSelfTestErrors(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	_newFakeClock(sensor)
+	mock := &_mockI2cConnection{
+		writeClosure: func(buf []byte) error { return nil },
+		readClosure: func(buf []byte) error {
+			return fmt.Errorf("read error")
+		},
+	}
+	sensor.i2cConnection = mock
+
+	if passed, err := sensor.SelfTest(); err == nil || passed {
+		t.Error("expected read error", passed, err)
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, []byte{0xd4, 0x00, 0x00})
+		return nil
+	}
+
+	if passed, err := sensor.SelfTest(); err == nil || passed {
+		t.Error("expected crc error", passed, err)
+	}
+}
+
+func TestSelfTestTimed(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	clock := _newFakeClock(sensor)
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error { return nil },
+		readClosure: func(buf []byte) error {
+			clock.Advance(80 * time.Millisecond)
+			copy(buf, []byte{0xd4, 0x00, 0xc6})
+			return nil
+		},
+	}
+
+	passed, elapsed, err := sensor.SelfTestTimed()
+	if err != nil || !passed {
+		t.Fatal("expected self test to pass", passed, err)
+	}
+
+	if expected := 300 * time.Millisecond; elapsed != expected {
+		t.Error("unexpected elapsed time", expected, elapsed)
+	}
+}
@@ -0,0 +1,80 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	SensirionLogHeader     = "Time\tCO2eq [ppm]\tTVOC [ppb]\tH2 raw\tEthanol raw\n"
+	SensirionLogTimeFormat = "2006-01-02 15:04:05.000"
+)
+
+// flusher is implemented by buffered writers such as *bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// StreamSensirionLog writes a reading every interval in the tab-separated
+// layout of Sensirion's ControlCenter exports until ctx is cancelled or the
+// sensor is closed. Buffered writers with a Flush method are flushed after
+// every row and before returning.
+func (s *SGP30Sensor) StreamSensirionLog(ctx context.Context, w io.Writer, interval time.Duration) (err error) {
+	if f, ok := w.(flusher); ok {
+		defer func() {
+			if flushErr := f.Flush(); err == nil {
+				err = flushErr
+			}
+		}()
+	}
+
+	if _, err := io.WriteString(w, SensirionLogHeader); err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if s.closed() {
+			return nil
+		}
+
+		eCO2, TVOC, err := s.MeasureContext(ctx)
+		if errors.Is(err, ErrDiscarded) {
+			if err := s.sleepContext(ctx, interval); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+		timestamp := s.clock.Now().Format(SensirionLogTimeFormat)
+
+		h2, ethanol, err := s.measureRaw(ctx)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", timestamp, eCO2, TVOC, h2, ethanol); err != nil {
+			return err
+		}
+
+		if f, ok := w.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+
+		if err := s.sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package sensor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamSensirionLog(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	clock := _newFakeClock(sensor)
+	_mockResponses(sensor, map[Command][]uint16{
+		MeasureAirQuality: {412, 35},
+		MeasureRawSignals: {13600, 19200},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock.onSleep = func(d time.Duration) {
+		if d == time.Second {
+			cancel()
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := sensor.StreamSensirionLog(ctx, buffer, time.Second); err != context.Canceled {
+		t.Error("expected context error", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatal("unexpected line count", 2, len(lines))
+	}
+
+	if lines[0]+"\n" != SensirionLogHeader {
+		t.Error("unexpected header", lines[0])
+	}
+
+	if lines[1] != "2020-01-01 00:00:00.000\t412\t35\t13600\t19200" {
+		t.Error("unexpected row", lines[1])
+	}
+}
+
+func TestStreamSensirionLogStopsOnClose(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		MeasureAirQuality: {412, 35},
+		MeasureRawSignals: {13600, 19200},
+	})
+	mock.closeClosure = func() error { return nil }
+
+	clock.onSleep = func(d time.Duration) {
+		if d == time.Second {
+			sensor.Close()
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := sensor.StreamSensirionLog(context.Background(), buffer, time.Second); err != nil {
+		t.Error("expected clean exit after Close", err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(buffer.String()), "\n"); len(lines) != 2 {
+		t.Error("expected no rows after Close", lines)
+	}
+}
+
+func TestStreamSensirionLogFlushesOnCancel(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	clock := _newFakeClock(sensor)
+	_mockResponses(sensor, map[Command][]uint16{
+		MeasureAirQuality: {412, 35},
+		MeasureRawSignals: {13600, 19200},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	buffer := &bytes.Buffer{}
+	writer := bufio.NewWriterSize(buffer, 4096)
+
+	rows := 0
+	clock.onSleep = func(d time.Duration) {
+		if d == time.Second {
+			rows++
+			if rows == 3 {
+				cancel()
+			}
+		}
+	}
+
+	if err := sensor.StreamSensirionLog(ctx, writer, time.Second); err != context.Canceled {
+		t.Error("expected context error", err)
+	}
+
+	if writer.Buffered() != 0 {
+		t.Error("expected nothing left buffered", writer.Buffered())
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatal("expected header and three rows", lines)
+	}
+
+	if lines[3] != "2020-01-01 00:00:02.050\t412\t35\t13600\t19200" {
+		t.Error("unexpected last row", lines[3])
+	}
+}
+
+func TestStreamSensirionLogCancelsMeasure(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	_mockResponses(sensor, map[Command][]uint16{
+		MeasureAirQuality: {412, 35},
+		MeasureRawSignals: {13600, 19200},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	measureDelay := sensor.cfg.CommandDelays[MeasureAirQuality]
+	clock.onSleep = func(d time.Duration) {
+		if d == measureDelay {
+			cancel()
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := sensor.StreamSensirionLog(ctx, buffer, time.Second); err != context.Canceled {
+		t.Error("expected context error", err)
+	}
+
+	if lines := strings.Split(strings.TrimSpace(buffer.String()), "\n"); len(lines) != 1 {
+		t.Error("expected no rows from a cancelled measurement", lines)
+	}
+}
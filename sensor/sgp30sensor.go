@@ -1,41 +1,55 @@
 package sensor
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"sync"
 	"time"
-
-	"github.com/op/go-logging"
-	"github.com/sigurn/crc8"
-	"golang.org/x/exp/io/i2c"
 )
 
 const (
-	InitAirQuality       uint16 = 0x2003
-	MeasureAirQuality    uint16 = 0x2008
-	GetBaseline          uint16 = 0x2015
-	SetBaseline          uint16 = 0x201e
-	SetHumidity          uint16 = 0x2061
-	MeasureTest          uint16 = 0x2032
-	GetFeatureSetVersion uint16 = 0x202f
-	MeasureRawSignals    uint16 = 0x2050
-	GetSerialID          uint16 = 0x3682
-	ExpectedFeatureSet   uint16 = 0x0020
+	InitAirQuality       Command = 0x2003
+	MeasureAirQuality    Command = 0x2008
+	GetBaseline          Command = 0x2015
+	SetBaseline          Command = 0x201e
+	SetHumidity          Command = 0x2061
+	MeasureTest          Command = 0x2032
+	GetFeatureSetVersion Command = 0x202f
+	MeasureRawSignals    Command = 0x2050
+	GetSerialID          Command = 0x3682
+	ExpectedFeatureSet   uint16  = 0x0020
 
 	Crc8Polynomial byte = 0x31
 	Crc8Init       byte = 0xFF
 	Crc8XorOut     byte = 0x00
 	Crc8Check      byte = 0xF7
 
-	DefaultI2CFsPath   string  = "/dev/i2c-1"
-	DefaultI2CAddr     byte    = 0x58
-	DefaultFrequency   float32 = 100000.0
-	DefaultDelayMillis int     = 10
+	DefaultI2CFsPath             string        = "/dev/i2c-1"
+	DefaultI2CAddr               byte          = 0x58
+	DefaultFrequency             float32       = 100000.0
+	DefaultDelay                 time.Duration = 10 * time.Millisecond
+	RawSignalsDelay              time.Duration = 25 * time.Millisecond
+	DefaultMeasureInterval       time.Duration = time.Second
+	DefaultHistorySize           int           = 3600
+	DefaultMaxWarmupMeasurements int           = 60
+	DefaultResetErrorRate        float64       = 0.5
+	MaxCombinedWords             int           = 4
+	// Deprecated: use DefaultDelay.
+	DefaultDelayMillis int = 10
 )
 
-type i2CConnection interface {
+// Connection is the I2C transport the sensor talks through. The default opens
+// I2CFsPath with i2c-dev; NewSensorWithConnection accepts any other.
+//
+// Read must fill all of buf or return an error; there is no byte count to
+// report a short read. Replies are cleared before each read so any bytes a
+// short read leaves behind fail the CRC check rather than repeating the last
+// reply.
+type Connection interface {
 	Read(buf []byte) error
 	ReadReg(reg byte, buf []byte) error
 	Write(buf []byte) error
@@ -44,97 +58,365 @@ type i2CConnection interface {
 }
 
 type Config struct {
-	I2CFsPath   string
-	I2CAddr     byte
-	Frequency   float32
-	Logger      *logging.Logger
+	// Name labels every measurement from this sensor, e.g. "living-room".
+	Name      string
+	I2CFsPath string
+	I2CAddr   byte
+	// Frequency is informational only. i2c-dev has no way to set the bus
+	// speed, which is fixed by the adapter driver or device tree, so Init
+	// just logs a warning when it differs from DefaultFrequency.
+	Frequency float32
+	// Logger defaults to NopLogger.
+	Logger Logger
+	// Delay is how long to wait for a reply to commands not in
+	// CommandDelays.
+	Delay time.Duration
+	// Deprecated: use Delay. A non-zero DelayMillis overrides Delay so
+	// existing configs keep their value.
 	DelayMillis int
+	// CommandDelays overrides the reply delay per command word. Commands not
+	// listed wait Delay.
+	CommandDelays      map[Command]time.Duration
+	MeasureInterval    time.Duration
+	MinMeasureInterval time.Duration
+	PollForReady       bool
+	// FallbackBaseline is written once warmup completes unless a baseline was
+	// restored since Init. It is only a starting point; the on-chip algorithm
+	// keeps refining the baseline from there.
+	FallbackBaseline *Baseline
+	// Trace receives a line for every write and read on the bus, which
+	// ReplayTrace can decode later.
+	Trace        io.Writer
+	QuantizeECO2 uint16
+	QuantizeTVOC uint16
+	// WaitForDevice is how long Init waits for I2CFsPath to appear.
+	WaitForDevice time.Duration
+	// AutoHumidityFunc, when set, is polled before each measurement to keep
+	// the humidity compensation current. Returning ok=false skips the update.
+	AutoHumidityFunc func() (relHumidity, tempC float64, ok bool)
+	// KeepaliveInterval, when set, reads the feature set this often after
+	// Init to keep idle links alive, reconnecting if the read fails.
+	KeepaliveInterval time.Duration
+	// ValidateRanges makes Measure reject readings outside the datasheet
+	// output range with ErrValueOutOfRange, which usually means a bus glitch
+	// that still passed the CRC.
+	ValidateRanges bool
+	// IAQThresholds classify readings from MeasureIAQ.
+	IAQThresholds IAQThresholds
+	// AutoReconnect reopens the connection and repeats the command once when
+	// a write or read fails, e.g. after a USB adapter is replugged.
+	AutoReconnect bool
+	HistorySize   int
+	Metrics       Metrics
+	// Retries is how many times a transaction is repeated after arbitration
+	// loss, a CRC mismatch or a failed read, waiting RetryDelay in between.
+	Retries    int
+	RetryDelay time.Duration
+	// SmoothingWindow, when set, replaces each reading with the moving average
+	// of the last SmoothingWindow readings, or of all so far until it fills.
+	// It runs before PostProcess.
+	SmoothingWindow int
+	// PostProcess runs inline on every reading before it is cached and
+	// returned.
+	PostProcess func(Measurement) Measurement
+	// MaxWarmupMeasurements caps how many readings Ready waits for the
+	// placeholder values to clear before giving up. Zero waits forever.
+	MaxWarmupMeasurements int
+	// InitRetries is how many times Init repeats a failed handshake,
+	// waiting InitRetryDelay between attempts.
+	InitRetries    int
+	InitRetryDelay time.Duration
+	// DiscardInitialReadings drops this many successful readings after Init,
+	// which come back as ErrDiscarded and are skipped by streams.
+	DiscardInitialReadings int
+	// ResetErrorRate is the share of the last ErrorRateWindow transactions
+	// that must fail before NeedsReset reports true. Zero disables it.
+	ResetErrorRate float64
+	// DelayStrategy, when set, picks the delay between writing a command and
+	// reading its reply, given the retry attempt starting from 0.
+	DelayStrategy func(cmd Command, attempt int) time.Duration
+	// ExpectedSerial, when non-zero, makes Init fail with ErrWrongSensor if
+	// the sensor reports a different serial.
+	ExpectedSerial uint64
+	// MaxBufferedSamples, when set, caps the history and baseline history
+	// samples kept in total, evicting the oldest first.
+	MaxBufferedSamples int
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		I2CFsPath:   DefaultI2CFsPath,
-		I2CAddr:     DefaultI2CAddr,
-		Frequency:   DefaultFrequency,
-		Logger:      nil,
-		DelayMillis: DefaultDelayMillis,
+		I2CFsPath:             DefaultI2CFsPath,
+		I2CAddr:               DefaultI2CAddr,
+		Frequency:             DefaultFrequency,
+		Logger:                NopLogger{},
+		Delay:                 DefaultDelay,
+		CommandDelays:         DefaultCommandDelays(),
+		MeasureInterval:       DefaultMeasureInterval,
+		HistorySize:           DefaultHistorySize,
+		MaxWarmupMeasurements: DefaultMaxWarmupMeasurements,
+		ResetErrorRate:        DefaultResetErrorRate,
+		IAQThresholds:         DefaultIAQThresholds,
 	}
 }
 
-func NewSensor(cfg *Config) *SGP30Sensor {
-	return &SGP30Sensor{
-		cfg: cfg,
-		crcTable: crc8.MakeTable(crc8.Params{
-			Poly:   Crc8Polynomial,
-			Init:   Crc8Init,
-			RefIn:  false,
-			RefOut: false,
-			XorOut: Crc8XorOut,
-			Check:  Crc8Check,
-		}),
+// DefaultCommandDelays returns the maximum command durations from the
+// datasheet.
+func DefaultCommandDelays() map[Command]time.Duration {
+	return map[Command]time.Duration{
+		InitAirQuality:       10 * time.Millisecond,
+		MeasureAirQuality:    12 * time.Millisecond,
+		GetBaseline:          10 * time.Millisecond,
+		SetBaseline:          10 * time.Millisecond,
+		SetHumidity:          10 * time.Millisecond,
+		MeasureTest:          SelfTestDelay,
+		GetFeatureSetVersion: 10 * time.Millisecond,
+		MeasureRawSignals:    RawSignalsDelay,
+		GetSerialID:          500 * time.Microsecond,
 	}
 }
 
+func NewSensor(cfg *Config) *SGP30Sensor {
+	return NewSensorWithOptions(WithConfig(cfg))
+}
+
+// NewSensorWithConnection uses conn instead of opening I2CFsPath, for other
+// I2C libraries, bus multiplexers or platforms without /dev/i2c-*.
+func NewSensorWithConnection(cfg *Config, conn Connection) *SGP30Sensor {
+	return NewSensorWithOptions(WithConfig(cfg), WithConnection(conn))
+}
+
 type SGP30Sensor struct {
-	cfg           *Config
-	i2cConnection i2CConnection
-	crcTable      *crc8.Table
-	SerialID      uint64
+	// cfg is never modified once shared; UpdateConfig swaps in a new one
+	// under cfgMu while holding txMu, so it is stable for a transaction.
+	cfgMu            sync.RWMutex
+	cfg              *Config
+	i2cConnection    Connection
+	clock            Clock
+	opener           Opener
+	bgMu             sync.Mutex
+	bg               background
+	keepaliveRunning bool
+	txMu             sync.Mutex
+	// commandBuffer and readBuffer hold the frame of a command without
+	// arguments and the longest fixed reply, the 3 word serial. They are only
+	// used under txMu.
+	commandBuffer    [2]byte
+	readBuffer       [9]byte
+	stateMu          sync.Mutex
+	state            State
+	initTime         time.Time
+	firstValidTime   time.Time
+	measureErrors    int
+	lastMeasure      time.Time
+	lastInterval     time.Duration
+	smoothing        movingAverage
+	baselineRestored bool
+	warmupReadings   int
+	ready            bool
+	readyDegraded    bool
+	discarded        int
+	crcErrors        int
+	readFailures     int
+	outcomes         [ErrorRateWindow]bool
+	outcomeCount     int
+	humidity         uint16
+	humiditySet      bool
+	lastRaw          Measurement
+	last             Measurement
+	baselineHistory  []baselineSample
+	history          measurementRing
+	featureSet       FeatureSet
+	SerialID         uint64
 }
 
 func (s *SGP30Sensor) Init() error {
-	if err := s.startI2CConnection(); err != nil {
-		s.logError(err.Error())
+	return s.InitContext(context.Background())
+}
+
+// InitContext is Init bounded by ctx, which cancels bus delays and retries.
+func (s *SGP30Sensor) InitContext(ctx context.Context) error {
+	return s.init(ctx, true)
+}
+
+// InitUnchecked runs the same handshake as Init but accepts any feature set,
+// for pre-production units in the lab.
+func (s *SGP30Sensor) InitUnchecked() error {
+	return s.init(context.Background(), false)
+}
+
+func (s *SGP30Sensor) init(ctx context.Context, checkFeatureSet bool) error {
+	s.setState(Connecting)
+	s.logBusSpeed()
+
+	for attempt := 0; ; attempt++ {
+		err := s.handshake(ctx, checkFeatureSet)
+		if err == nil {
+			break
+		}
+
+		if ctx.Err() != nil {
+			s.setState(Disconnected)
+			return ctx.Err()
+		}
+
+		if attempt >= s.config().InitRetries {
+			s.setState(Disconnected)
+			return err
+		}
+
+		s.logError("init attempt %d failed: %s", attempt+1, err)
+		if err := s.sleepContext(ctx, s.config().InitRetryDelay); err != nil {
+			s.setState(Disconnected)
+			return err
+		}
+	}
+
+	s.startWarmup()
+	s.startKeepalive()
+
+	return nil
+}
+
+func (s *SGP30Sensor) handshake(ctx context.Context, checkFeatureSet bool) error {
+	expectedSerial := s.config().ExpectedSerial
+	if err := s.startI2CConnection(ctx); err != nil {
+		s.logError("%s", err)
+		return err
+	}
+
+	if err := s.sleepContext(ctx, s.delay()); err != nil {
 		return err
 	}
-	s.delay(s.cfg.DelayMillis)
 
-	if serial, err := s.getSerial(); err == nil {
+	if serial, err := s.getSerial(ctx); err == nil {
 		s.SerialID = serial
 	} else {
 		s.SerialID = 0
 		s.logError("failed to get serial: %s", err)
+
+		var serialErr *SerialReadError
+		if errors.As(err, &serialErr) {
+			return err
+		}
+
+		if expectedSerial != 0 {
+			return fmt.Errorf("%w: could not read serial to compare with %x: %s", ErrWrongSensor, expectedSerial, err)
+		}
+	}
+
+	if expectedSerial != 0 && s.SerialID != expectedSerial {
+		s.logError("expected serial %x, found %x", expectedSerial, s.SerialID)
+		return fmt.Errorf("%w: expected serial %x, found %x", ErrWrongSensor, expectedSerial, s.SerialID)
 	}
 
-	if featureSet, err := s.getFeatureSet(); err == nil {
-		if featureSet != ExpectedFeatureSet {
-			s.logError("sgp30 featureset mismatch: %x", featureSet)
-			return fmt.Errorf("sgp30 sensor not found")
+	if featureSet, err := s.getFeatureSet(ctx); err == nil {
+		s.featureSet = parseFeatureSet(featureSet)
+		if !checkFeatureSet {
+			s.logInfo("skipping feature set check: %x", featureSet)
+		} else if s.featureSet.ProductType != ProductTypeSGP30 {
+			model := s.featureSet.Model()
+			s.logError("wrong sensor model %s: %x", model, featureSet)
+			return fmt.Errorf("%w: detected %s", ErrWrongSensorModel, model)
 		}
 	} else {
 		s.logError("failed to get feature set")
 		return fmt.Errorf("sgp30 sensor not found")
 	}
 
-	if _, err := s.readWordsUint(InitAirQuality, 0); err != nil {
+	if _, err := s.readWordsUintContext(ctx, InitAirQuality, 0); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Close stops keepalive and streaming goroutines, waits for them to exit,
+// then closes the connection.
 func (s *SGP30Sensor) Close() error {
+	s.stopBackground()
+
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
 	if s.i2cConnection == nil {
 		return fmt.Errorf("connection already closed")
 	}
 
 	err := s.i2cConnection.Close()
 	s.i2cConnection = nil
+	s.setState(Closed)
 
 	return err
 }
 
 func (s *SGP30Sensor) Measure() (eCO2 uint16, TVOC uint16, err error) {
-	vals, err := s.readWordsUint(MeasureAirQuality, 2)
+	return s.MeasureContext(context.Background())
+}
+
+// MeasureContext is Measure bounded by ctx, which cancels the bus delay.
+func (s *SGP30Sensor) MeasureContext(ctx context.Context) (eCO2 uint16, TVOC uint16, err error) {
+	m, err := s.measureChecked(ctx)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	return vals[0], vals[1], err
+	return m.ECO2, m.TVOC, err
 }
 
-func (s *SGP30Sensor) GetBaseline() (eCO2 uint16, TVOC uint16, err error) {
-	vals, err := s.readWordsUint(GetBaseline, 2)
+func (s *SGP30Sensor) measureChecked(ctx context.Context) (Measurement, error) {
+	if err := s.checkCadence(); err != nil {
+		return Measurement{}, err
+	}
+
+	return s.measure(ctx)
+}
+
+func (s *SGP30Sensor) measure(ctx context.Context) (Measurement, error) {
+	cfg := s.config()
+	s.applyAutoHumidity(cfg)
+
+	var vals [2]uint16
+	err := s.readInto(ctx, MeasureAirQuality, vals[:])
+	if err != nil && ctx.Err() != nil {
+		return Measurement{}, err
+	}
+
+	if err == nil && cfg.ValidateRanges {
+		err = checkRange(vals[0], vals[1])
+	}
+
+	warmedUp := s.trackMeasureResult(err)
+	if err != nil {
+		return Measurement{}, err
+	}
+
+	m := Measurement{ECO2: vals[0], TVOC: vals[1], Timestamp: s.clock.Now(), SerialID: s.SerialID, SensorName: cfg.Name}
+	m.InWarmup = !s.warmedUpAt(m.Timestamp)
+	m.OffCadence = s.trackMeasureTime(m.Timestamp)
+
+	if warmedUp {
+		s.applyFallbackBaseline(cfg)
+	}
+
+	if s.discardInitial(cfg) {
+		return Measurement{}, ErrDiscarded
+	}
+
+	s.trackFirstValid(m)
+	s.trackReadiness(m, cfg)
+
+	m = s.process(m, cfg)
+	s.recordHistory(m, cfg)
+
+	return m, nil
+}
+
+func (s *SGP30Sensor) MeasureRaw() (h2 uint16, ethanol uint16, err error) {
+	return s.measureRaw(context.Background())
+}
+
+func (s *SGP30Sensor) measureRaw(ctx context.Context) (h2 uint16, ethanol uint16, err error) {
+	vals, err := s.readWordsDelay(ctx, commandFrame(MeasureRawSignals), 2, RawSignalsDelay)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -142,29 +424,56 @@ func (s *SGP30Sensor) GetBaseline() (eCO2 uint16, TVOC uint16, err error) {
 	return vals[0], vals[1], nil
 }
 
+func (s *SGP30Sensor) GetBaseline() (eCO2 uint16, TVOC uint16, err error) {
+	return s.GetBaselineContext(context.Background())
+}
+
+func (s *SGP30Sensor) GetBaselineContext(ctx context.Context) (eCO2 uint16, TVOC uint16, err error) {
+	var vals [2]uint16
+	if err := s.readInto(ctx, GetBaseline, vals[:]); err != nil {
+		return 0, 0, err
+	}
+
+	s.recordBaseline(Baseline{ECO2: vals[0], TVOC: vals[1], CapturedTime: s.clock.Now()})
+
+	return vals[0], vals[1], nil
+}
+
 func (s *SGP30Sensor) SetBaseline(eCO2 uint16, TVOC uint16) error {
-	buffer := make([]byte, 2)
-	binary.BigEndian.PutUint16(buffer, SetBaseline)
+	return s.SetBaselineContext(context.Background(), eCO2, TVOC)
+}
 
-	buffer = append(buffer, s.packWordCrc(eCO2)...)
-	buffer = append(buffer, s.packWordCrc(TVOC)...)
+func (s *SGP30Sensor) SetBaselineContext(ctx context.Context, eCO2 uint16, TVOC uint16) error {
+	if _, err := s.readWordsContext(ctx, CommandBytes(SetBaseline, eCO2, TVOC), 0); err != nil {
+		return err
+	}
 
-	_, err := s.readWords(buffer, 0)
+	s.stateMu.Lock()
+	s.baselineRestored = true
+	s.stateMu.Unlock()
 
-	return err
+	return nil
 }
 
-func (s *SGP30Sensor) getSerial() (uint64, error) {
-	vals, err := s.readWordsUint(GetSerialID, 3)
+// getSerial reads the 3 word serial. A CRC failure on any of the 9 reply
+// bytes comes back as a SerialReadError. Connection.Read has no byte count, so
+// a short read is caught the same way: the reply buffer is cleared first and
+// the missing bytes fail their CRC.
+func (s *SGP30Sensor) getSerial(ctx context.Context) (uint64, error) {
+	vals, err := s.readWordsUintContext(ctx, GetSerialID, 3)
+	if IsCRCError(err) {
+		return 0, &SerialReadError{Err: err}
+	}
+
 	if err != nil {
 		return 0, fmt.Errorf("failed to read serial: %s", err)
 	}
 
-	return s.combineWords(vals), nil
+	return s.combineWords(vals)
 }
 
-func (s *SGP30Sensor) getFeatureSet() (uint16, error) {
-	vals, err := s.readWordsUint(GetFeatureSetVersion, 1)
+func (s *SGP30Sensor) getFeatureSet(ctx context.Context) (uint16, error) {
+	vals, err := s.readWordsUintContext(ctx, GetFeatureSetVersion, 1)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get feature set: %s", err)
 	}
@@ -172,101 +481,224 @@ func (s *SGP30Sensor) getFeatureSet() (uint16, error) {
 	return vals[0], nil
 }
 
-func (s *SGP30Sensor) startI2CConnection() error {
+func (s *SGP30Sensor) startI2CConnection(ctx context.Context) error {
 	if s.i2cConnection != nil {
 		s.logError("i2cconnection already started")
 		return nil
 	}
 
-	if _, err := os.Stat(s.cfg.I2CFsPath); err != nil {
-		return fmt.Errorf("i2c FS path not found")
+	if s.opener == nil {
+		return ErrNoOpener
 	}
 
-	device, err := i2c.Open(&i2c.Devfs{Dev: s.cfg.I2CFsPath}, int(s.cfg.I2CAddr))
-	s.i2cConnection = device
+	if err := s.waitForDevice(ctx); err != nil {
+		return err
+	}
 
-	return err
+	cfg := s.config()
+	device, err := s.opener.Open(cfg.I2CFsPath, cfg.I2CAddr)
+	if err != nil {
+		return err
+	}
+
+	s.i2cConnection = s.traced(device)
+
+	return nil
 }
 
 func (s *SGP30Sensor) packWordCrc(word uint16) []byte {
-	buffer := make([]byte, 2)
-	binary.BigEndian.PutUint16(buffer, word)
-	buffer = append(buffer, s.generateCrc(buffer))
-
-	return buffer
+	return packWordCrc(word)
 }
 
-func (s *SGP30Sensor) readWordsUint(command uint16, replySize int) (result []uint16, err error) {
-	buffer := make([]byte, 2)
-	binary.BigEndian.PutUint16(buffer, command)
+func (s *SGP30Sensor) readWordsUint(command Command, replySize int) (result []uint16, err error) {
+	return s.readWordsUintContext(context.Background(), command, replySize)
+}
 
-	return s.readWords(buffer, replySize)
+func (s *SGP30Sensor) readWordsUintContext(ctx context.Context, command Command, replySize int) (result []uint16, err error) {
+	return s.readWordsContext(ctx, commandFrame(command), replySize)
 }
 
-func (s *SGP30Sensor) combineWords(words []uint16) uint64 {
-	combined := make([]byte, 8)
+// combineWords packs up to MaxCombinedWords big-endian words into a uint64,
+// the first word most significant.
+func (s *SGP30Sensor) combineWords(words []uint16) (uint64, error) {
+	if len(words) > MaxCombinedWords {
+		return 0, fmt.Errorf("cannot combine %d words, max is %d", len(words), MaxCombinedWords)
+	}
 
-	for i := range words {
-		buf := make([]byte, 2)
-		binary.BigEndian.PutUint16(buf, words[len(words)-1-i])
-		combined[7-2*i] = buf[1]
-		combined[7-(2*i+1)] = buf[0]
+	var combined uint64
+	for _, word := range words {
+		combined = combined<<16 | uint64(word)
 	}
 
-	return binary.BigEndian.Uint64(combined)
+	return combined, nil
 }
 
 func (s *SGP30Sensor) readWords(command []byte, replySize int) (result []uint16, err error) {
+	return s.readWordsContext(context.Background(), command, replySize)
+}
+
+func (s *SGP30Sensor) readWordsContext(ctx context.Context, command []byte, replySize int) (result []uint16, err error) {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	return s.readWordsLocked(ctx, command, replySize, s.delay())
+}
+
+func (s *SGP30Sensor) readWordsDelay(ctx context.Context, command []byte, replySize int, delay time.Duration) (result []uint16, err error) {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	return s.readWordsLocked(ctx, command, replySize, delay)
+}
+
+// readWordsLocked expects txMu to be held.
+func (s *SGP30Sensor) readWordsLocked(ctx context.Context, command []byte, replySize int, delay time.Duration) (result []uint16, err error) {
+	if replySize > 0 {
+		result = make([]uint16, replySize)
+	}
+
+	if err := s.transactRetry(ctx, command, result, delay); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readInto runs a command without arguments and decodes the reply into dst
+// without allocating, reusing the sensor's command and read buffers.
+func (s *SGP30Sensor) readInto(ctx context.Context, command Command, dst []uint16) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.commandBuffer = fixedFrame(command)
+
+	return s.transactRetry(ctx, s.commandBuffer[:], dst, s.delay())
+}
+
+func (s *SGP30Sensor) transact(ctx context.Context, command []byte, dst []uint16, delay time.Duration) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cfg := s.config()
+	if cfg.Metrics != nil && len(command) >= 2 {
+		start := s.clock.Now()
+		defer func() {
+			cfg.Metrics.ObserveTransaction(Command(binary.BigEndian.Uint16(command)), s.clock.Now().Sub(start))
+		}()
+	}
+
 	if s.i2cConnection == nil {
-		return nil, fmt.Errorf("i2c not connected")
+		return fmt.Errorf("i2c not connected")
 	}
 
 	err = s.i2cConnection.Write(command)
 	if err != nil {
+		s.readFailures++
 		s.logError("failed writing command %s: %s", hex.Dump(command), err.Error())
-		return result, err
+		return &writeError{err: mapBusError(err)}
 	}
 
-	s.delay(s.cfg.DelayMillis)
+	replySize := len(dst)
 	if replySize == 0 {
-		return result, nil
+		return s.sleepContext(ctx, delay)
+	}
+
+	var crcResult []byte
+	if replySize*3 <= len(s.readBuffer) {
+		crcResult = s.readBuffer[:replySize*3]
+	} else {
+		crcResult = make([]byte, replySize*3)
 	}
+	clearBytes(crcResult)
 
-	crcResult := make([]byte, replySize*(3))
-	err = s.i2cConnection.Read(crcResult)
+	if cfg.PollForReady {
+		err = s.pollRead(ctx, crcResult)
+	} else if err = s.sleepContext(ctx, delay); err == nil {
+		err = s.i2cConnection.Read(crcResult)
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+
+		s.readFailures++
 		s.logError("failed read: %s", err)
-		return result, err
+		return &readError{err: mapBusError(err)}
 	}
 
-	result = make([]uint16, replySize)
-
 	for i := 0; i < replySize; i++ {
-		word := []byte{crcResult[3*i], crcResult[3*i+1]}
+		word := crcResult[3*i : 3*i+2]
 		crc := crcResult[3*i+2]
 
 		generatedCrc := s.generateCrc(word)
 		if generatedCrc != crc {
+			s.crcErrors++
 			s.logError("crc mismatch %+v, %+v", crc, generatedCrc)
-			return nil, fmt.Errorf("crc mismatch %x, %x", crc, generatedCrc)
+			return &CRCError{Expected: generatedCrc, Got: crc, WordIndex: i}
 		}
 
-		result[i] = binary.BigEndian.Uint16([]byte{word[0], word[1]})
+		dst[i] = binary.BigEndian.Uint16(word)
 	}
 
-	return result, nil
+	return nil
+}
+
+// clearBytes zeroes buf so stale bytes can't survive a short read. A zeroed
+// word never matches its CRC.
+func clearBytes(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
 }
 
 func (s *SGP30Sensor) generateCrc(data []byte) byte {
-	return crc8.Checksum(data, s.crcTable)
+	return ComputeCRC(data)
 }
 
-func (s *SGP30Sensor) delay(delayMillis int) {
-	time.Sleep(time.Millisecond * time.Duration(delayMillis))
+// delay is the configured reply delay, the deprecated DelayMillis when set or
+// else Delay.
+func (s *SGP30Sensor) delay() time.Duration {
+	cfg := s.config()
+	if cfg.DelayMillis != 0 {
+		return millis(cfg.DelayMillis)
+	}
+
+	return cfg.Delay
 }
 
-func (s *SGP30Sensor) logError(msg string, params ...interface{}) {
-	if s.cfg.Logger != nil {
-		s.cfg.Logger.Errorf(msg, params)
+// commandDelay is how long to wait for a reply to command, from DelayStrategy
+// when one is set, then CommandDelays, then fallback.
+func (s *SGP30Sensor) commandDelay(command []byte, attempt int, fallback time.Duration) time.Duration {
+	if len(command) < 2 {
+		return fallback
 	}
+
+	cfg := s.config()
+	cmd := Command(binary.BigEndian.Uint16(command))
+	if cfg.DelayStrategy != nil {
+		return cfg.DelayStrategy(cmd, attempt)
+	}
+
+	if delay, ok := cfg.CommandDelays[cmd]; ok {
+		return delay
+	}
+
+	return fallback
+}
+
+func millis(n int) time.Duration {
+	return time.Millisecond * time.Duration(n)
+}
+
+func (s *SGP30Sensor) logError(msg string, params ...interface{}) {
+	s.config().Logger.Errorf(msg, params...)
+}
+
+func (s *SGP30Sensor) logInfo(msg string, params ...interface{}) {
+	s.config().Logger.Infof(msg, params...)
+}
+
+func (s *SGP30Sensor) logDebug(msg string, params ...interface{}) {
+	s.config().Logger.Debugf(msg, params...)
 }
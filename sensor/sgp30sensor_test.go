@@ -1,9 +1,15 @@
 package sensor
 
 import (
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestCrcGeneration(t *testing.T) {
@@ -54,17 +60,28 @@ func TestCombineWords(t *testing.T) {
 		words            []uint16
 		expectedCombined uint64
 	}{
+		{[]uint16{}, 0},
+		{[]uint16{0xbeef}, 0xbeef},
 		{[]uint16{0x1234, 0x5678}, 0x12345678},
 		{[]uint16{0x4321, 0x8765, 0xdcbe}, 0x43218765dcbe},
+		{[]uint16{0x0102, 0x0304, 0x0506}, 0x010203040506},
 		{[]uint16{0x1234, 0x5678, 0x90ab, 0xcdef}, 0x1234567890abcdef},
 	}
 
 	for _, row := range table {
-		combined := sensor.combineWords(row.words)
+		combined, err := sensor.combineWords(row.words)
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+
 		if combined != row.expectedCombined {
 			t.Errorf("mismatched result %x, %x", row.expectedCombined, combined)
 		}
 	}
+
+	if _, err := sensor.combineWords([]uint16{1, 2, 3, 4, 5}); err == nil {
+		t.Error("expected error for too many words")
+	}
 }
 
 func TestReadWordsChecksConnection(t *testing.T) {
@@ -127,14 +144,15 @@ func TestInit(t *testing.T) {
 		} else if _bytesMatchUint(buf, GetSerialID) {
 			readOutput = []byte{0x01, 0x02, 0x17, 0x03, 0x04, 0x68, 0x05, 0x06, 0x0}
 		} else if _bytesMatchUint(buf, GetFeatureSetVersion) {
-			readOutput = []byte{0x01, 0x02, 0x17}
+			readOutput = []byte{0x20, 0x06, 0xfb}
 		}
 
 		return nil
 	}
 
-	if err := sensor.Init(); err == nil {
-		t.Error("expected error")
+	var serialErr *SerialReadError
+	if err := sensor.Init(); !errors.As(err, &serialErr) || !IsCRCError(err) {
+		t.Error("expected serial read error", err)
 	}
 
 	if sensor.SerialID != 0 {
@@ -158,6 +176,53 @@ func TestInit(t *testing.T) {
 	}
 }
 
+func TestInitRetries(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.InitRetries = 2
+	cfg.InitRetryDelay = 500 * time.Millisecond
+	sensor := NewSensor(cfg)
+	clock := _newFakeClock(sensor)
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+
+	readClosure := mock.readClosure
+	naks := 2
+	mock.readClosure = func(buf []byte) error {
+		if naks > 0 {
+			naks--
+			return &os.PathError{Op: "read", Path: DefaultI2CFsPath, Err: errNAK}
+		}
+
+		return readClosure(buf)
+	}
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if sensor.SerialID != 0x010203040506 || sensor.State() != Warmup {
+		t.Errorf("expected second attempt to succeed, %x, %s", sensor.SerialID, sensor.State())
+	}
+
+	retried := false
+	for _, d := range clock.sleeps {
+		if d == cfg.InitRetryDelay {
+			retried = true
+		}
+	}
+
+	if !retried {
+		t.Error("expected a retry delay", clock.sleeps)
+	}
+
+	naks = 2 * (cfg.InitRetries + 1)
+	if err := sensor.Init(); err == nil || sensor.State() != Disconnected {
+		t.Error("expected error once retries are exhausted", err, sensor.State())
+	}
+}
+
 func TestClose(t *testing.T) {
 	sensor := NewSensor(DefaultConfig())
 	if err := sensor.Close(); err == nil {
@@ -207,7 +272,7 @@ func TestReadWords(t *testing.T) {
 	}
 
 	sensor := NewSensor(DefaultConfig())
-	sensor.cfg.DelayMillis = 0
+	sensor.cfg.Delay = 0
 	sensor.i2cConnection = mock
 
 	val, err := sensor.readWords([]byte{0x23}, 1)
@@ -223,7 +288,7 @@ func TestReadWords(t *testing.T) {
 func TestReadWordsHandlesErrors(t *testing.T) {
 	mock := &_mockI2cConnection{}
 	sensor := NewSensor(DefaultConfig())
-	sensor.cfg.DelayMillis = 0
+	sensor.cfg.Delay = 0
 	sensor.i2cConnection = mock
 
 	mock.writeClosure = func(buf []byte) error {
@@ -253,7 +318,7 @@ func TestReadWordsHandlesErrors(t *testing.T) {
 func TestReadWordsHandlesCrcMismatch(t *testing.T) {
 	mock := &_mockI2cConnection{}
 	sensor := NewSensor(DefaultConfig())
-	sensor.cfg.DelayMillis = 0
+	sensor.cfg.Delay = 0
 	sensor.i2cConnection = mock
 
 	mock.readClosure = func(buf []byte) error {
@@ -267,15 +332,26 @@ func TestReadWordsHandlesCrcMismatch(t *testing.T) {
 		return nil
 	}
 
-	if _, err := sensor.readWords(nil, 1); err == nil {
-		t.Error("expected error")
+	_, err := sensor.readWords(nil, 1)
+
+	var crcErr *CRCError
+	if !errors.As(err, &crcErr) {
+		t.Fatal("expected crc error", err)
+	}
+
+	if crcErr.Expected != 0x17 || crcErr.Got != 0x03 || crcErr.WordIndex != 0 {
+		t.Errorf("unexpected crc error, %+v", crcErr)
+	}
+
+	if !IsCRCError(fmt.Errorf("wrapped: %w", err)) || IsCRCError(fmt.Errorf("bus error")) {
+		t.Error("unexpected IsCRCError result")
 	}
 }
 
 func TestMeasure(t *testing.T) {
 	mock := &_mockI2cConnection{}
 	sensor := NewSensor(DefaultConfig())
-	sensor.cfg.DelayMillis = 0
+	sensor.cfg.Delay = 0
 	sensor.i2cConnection = mock
 
 	mock.writeClosure = func(buf []byte) error {
@@ -334,10 +410,67 @@ func TestMeasure(t *testing.T) {
 	}
 }
 
+func TestMeasureRaw(t *testing.T) {
+	mock := &_mockI2cConnection{}
+	sensor := NewSensor(DefaultConfig())
+	clock := _newFakeClock(sensor)
+	sensor.i2cConnection = mock
+
+	mock.writeClosure = func(buf []byte) error {
+		if len(buf) != 2 || buf[0] != 0x20 || buf[1] != 0x50 {
+			t.Error("unexpected write value", 0x2050, buf)
+		}
+
+		return nil
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		if len(buf) != 6 {
+			t.Fatal("unexpected read buffer length")
+		}
+
+		buf[0] = 0x01
+		buf[1] = 0x02
+		buf[2] = 0x17
+		buf[3] = 0x03
+		buf[4] = 0x04
+		buf[5] = 0x68
+
+		return nil
+	}
+
+	h2, ethanol, err := sensor.MeasureRaw()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if h2 != 0x0102 {
+		t.Errorf("unexpected h2 value, %x, %x", 0x0102, h2)
+	}
+
+	if ethanol != 0x0304 {
+		t.Errorf("unexpected ethanol value, %x, %x", 0x0304, ethanol)
+	}
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != RawSignalsDelay {
+		t.Error("expected the raw signals delay", clock.sleeps)
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, []byte{0x01, 0x02, 0x17, 0x03, 0x04, 0x00})
+
+		return nil
+	}
+
+	if _, _, err := sensor.MeasureRaw(); err == nil {
+		t.Error("expected error")
+	}
+}
+
 func TestGetSerialNumber(t *testing.T) {
 	mock := &_mockI2cConnection{}
 	sensor := NewSensor(DefaultConfig())
-	sensor.cfg.DelayMillis = 0
+	sensor.cfg.Delay = 0
 	sensor.i2cConnection = mock
 
 	mock.writeClosure = func(buf []byte) error {
@@ -360,7 +493,7 @@ func TestGetSerialNumber(t *testing.T) {
 		return nil
 	}
 
-	val, err := sensor.getSerial()
+	val, err := sensor.getSerial(context.Background())
 	if err != nil {
 		t.Error("unexpected err", err)
 	}
@@ -373,19 +506,42 @@ func TestGetSerialNumber(t *testing.T) {
 		return fmt.Errorf("error")
 	}
 
-	if _, err := sensor.getSerial(); err == nil {
+	if _, err := sensor.getSerial(context.Background()); err == nil {
 		t.Error("expected error")
 	}
 }
 
 func TestGetFeatureSet(t *testing.T) {
+	table := []struct {
+		word     uint16
+		expected FeatureSet
+		model    string
+	}{
+		{0x0020, FeatureSet{ProductType: ProductTypeSGP30, ProductVersion: 0x20}, "SGP30"},
+		{0x0022, FeatureSet{ProductType: ProductTypeSGP30, ProductVersion: 0x22}, "SGP30"},
+		{0x1006, FeatureSet{ProductType: ProductTypeSGPC3, ProductVersion: 0x06}, "SGPC3"},
+	}
+
+	for _, row := range table {
+		sensor := NewSensor(DefaultConfig())
+		sensor.cfg.Delay = 0
+		_mockResponses(sensor, map[Command][]uint16{GetFeatureSetVersion: {row.word}})
 
+		featureSet, err := sensor.GetFeatureSet()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if featureSet != row.expected || featureSet.Model() != row.model {
+			t.Errorf("unexpected feature set for %x, %+v", row.word, featureSet)
+		}
+	}
 }
 
 func TestGetBaseline(t *testing.T) {
 	mock := &_mockI2cConnection{}
 	sensor := NewSensor(DefaultConfig())
-	sensor.cfg.DelayMillis = 0
+	sensor.cfg.Delay = 0
 	sensor.i2cConnection = mock
 
 	mock.writeClosure = func(buf []byte) error {
@@ -443,7 +599,7 @@ func TestGetBaseline(t *testing.T) {
 func TestSetBaseline(t *testing.T) {
 	mock := &_mockI2cConnection{}
 	sensor := NewSensor(DefaultConfig())
-	sensor.cfg.DelayMillis = 0
+	sensor.cfg.Delay = 0
 	sensor.i2cConnection = mock
 
 	mock.writeClosure = func(buf []byte) error {
@@ -474,8 +630,8 @@ func _bytesMatch(a []byte, b []byte) bool {
 	return true
 }
 
-func _bytesMatchUint(a []byte, intVal uint16) bool {
-	return binary.BigEndian.Uint16(a) == intVal
+func _bytesMatchUint(a []byte, cmd Command) bool {
+	return Command(binary.BigEndian.Uint16(a)) == cmd
 }
 
 type _mockI2cConnection struct {
@@ -505,3 +661,330 @@ func (m *_mockI2cConnection) WriteReg(reg byte, buf []byte) (err error) {
 func (m *_mockI2cConnection) Close() error {
 	return m.closeClosure()
 }
+
+func _mockResponses(sensor *SGP30Sensor, responses map[Command][]uint16) *_mockI2cConnection {
+	mock := &_mockI2cConnection{}
+	sensor.i2cConnection = mock
+
+	var readOutput []byte
+
+	mock.writeClosure = func(buf []byte) error {
+		readOutput = nil
+		for _, word := range responses[Command(binary.BigEndian.Uint16(buf))] {
+			readOutput = append(readOutput, sensor.packWordCrc(word)...)
+		}
+
+		return nil
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, readOutput)
+
+		return nil
+	}
+
+	return mock
+}
+
+func _mockMeasurements(sensor *SGP30Sensor, readings [][2]uint16) *_mockI2cConnection {
+	mock := &_mockI2cConnection{}
+	sensor.i2cConnection = mock
+
+	var readOutput []byte
+	next := 0
+
+	mock.writeClosure = func(buf []byte) error {
+		readOutput = nil
+		if _bytesMatchUint(buf, MeasureAirQuality) {
+			reading := readings[next%len(readings)]
+			next++
+			readOutput = append(sensor.packWordCrc(reading[0]), sensor.packWordCrc(reading[1])...)
+		}
+
+		return nil
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, readOutput)
+
+		return nil
+	}
+
+	return mock
+}
+
+type _fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	sleeps  []time.Duration
+	onSleep func(d time.Duration)
+}
+
+func _newFakeClock(sensor *SGP30Sensor) *_fakeClock {
+	clock := &_fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	sensor.clock = clock
+
+	return clock
+}
+
+func (c *_fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *_fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+	onSleep := c.onSleep
+	c.mu.Unlock()
+
+	if onSleep != nil {
+		onSleep(d)
+	}
+}
+
+func (c *_fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestBaselineContextCancelled(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	mock := &_mockI2cConnection{}
+	sensor.i2cConnection = mock
+
+	writes := 0
+	mock.writeClosure = func(buf []byte) error {
+		writes++
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := sensor.GetBaselineContext(ctx); err != context.Canceled {
+		t.Error("expected context error", err)
+	}
+
+	if err := sensor.SetBaselineContext(ctx, 0x0102, 0x0304); err != context.Canceled {
+		t.Error("expected context error", err)
+	}
+
+	if writes != 0 {
+		t.Error("expected no writes with a cancelled context", writes)
+	}
+}
+
+func TestBaselineContextCancelledDuringDelay(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 10 * time.Second
+	sensor.cfg.CommandDelays = nil
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := sensor.SetBaselineContext(ctx, 0x0102, 0x0304); err != context.DeadlineExceeded {
+		t.Error("expected context error", err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Error("expected cancellation to interrupt the delay")
+	}
+}
+
+func TestInitExpectedSerial(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.ExpectedSerial = 0x010203040506
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	sensor.cfg.ExpectedSerial = 0x0a0b0c0d0e0f
+
+	err := sensor.Init()
+	if !errors.Is(err, ErrWrongSensor) {
+		t.Fatal("expected wrong sensor error", err)
+	}
+
+	if !strings.Contains(err.Error(), "a0b0c0d0e0f") || !strings.Contains(err.Error(), "10203040506") {
+		t.Error("expected error to include both serials", err)
+	}
+}
+
+func TestInitUnchecked(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x1042},
+	})
+
+	if err := sensor.Init(); err == nil {
+		t.Error("expected Init to reject the feature set")
+	}
+
+	if err := sensor.InitUnchecked(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if sensor.State() != Warmup || sensor.featureSet.ProductVersion != 0x42 {
+		t.Error("expected unchecked init to complete", sensor.State(), sensor.featureSet)
+	}
+
+	logger := &_captureLogger{}
+	sensor.cfg.Logger = logger
+	if err := sensor.InitUnchecked(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	logged := false
+	for _, line := range logger.lines {
+		if strings.Contains(line, "skipping feature set check") {
+			logged = line == "INFO skipping feature set check: 1042"
+		}
+	}
+
+	if !logged {
+		t.Error("expected the accepted feature set logged at info", logger.lines)
+	}
+}
+
+func TestMeasureContextCancelledDuringDelay(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 10 * time.Second
+	_mockMeasurements(sensor, [][2]uint16{{412, 3}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, _, err := sensor.MeasureContext(ctx); err != context.DeadlineExceeded {
+		t.Error("expected context error", err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Error("expected cancellation to interrupt the delay")
+	}
+
+	if sensor.measureErrors != 0 {
+		t.Error("expected cancellation not to count as a measurement error", sensor.measureErrors)
+	}
+}
+
+func TestInitContextCancelledDuringDelay(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 10 * time.Second
+	_mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := sensor.InitContext(ctx); err != context.DeadlineExceeded {
+		t.Error("expected context error", err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Error("expected cancellation to interrupt the delay")
+	}
+
+	if sensor.State() != Disconnected {
+		t.Error("expected disconnected state", sensor.State())
+	}
+}
+
+func TestNewSensorWithConnection(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	mock := _mockResponses(NewSensor(cfg), map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+	})
+	sensor := NewSensorWithConnection(cfg, mock)
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			t.Error("unexpected stat", name)
+			return nil, os.ErrNotExist
+		},
+	}
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if sensor.SerialID != 0x010203040506 {
+		t.Errorf("unexpected serial, %x", sensor.SerialID)
+	}
+}
+
+func TestShortReadFailsCRC(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	_newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, packWordCrc(450))
+		return nil
+	}
+
+	_, _, err := sensor.Measure()
+	crcErr, ok := err.(*CRCError)
+	if !ok {
+		t.Fatal("expected crc error from the unfilled word", err)
+	}
+
+	if crcErr.WordIndex != 1 {
+		t.Error("expected the second word to fail", crcErr.WordIndex)
+	}
+
+	reads := 0
+	mock.readClosure = func(buf []byte) error {
+		reads++
+		if reads == 1 {
+			copy(buf, packWordCrc(0x1111))
+		}
+
+		return nil
+	}
+
+	if _, err := sensor.SendCommand(0x3702, ReadUntilNAK); err == nil {
+		t.Error("expected a read that fills nothing to fail the crc check")
+	}
+
+	mock.readClosure = func(buf []byte) error {
+		copy(buf, []byte{0x01, 0x02, 0x17, 0x03, 0x04, 0x68})
+		return nil
+	}
+
+	var serialErr *SerialReadError
+	if _, err := sensor.getSerial(context.Background()); !errors.As(err, &serialErr) {
+		t.Error("expected a short serial read to be a serial read error", err)
+	}
+}
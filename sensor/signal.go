@@ -0,0 +1,52 @@
+package sensor
+
+import "context"
+
+// MeasureOnSignal measures each time a value arrives on ready, such as a GPIO
+// data-ready interrupt, instead of on a timer. Failed readings are logged and
+// skipped. The returned channel closes when ctx is cancelled, ready closes or
+// the sensor is closed.
+func (s *SGP30Sensor) MeasureOnSignal(ctx context.Context, ready <-chan struct{}) <-chan Measurement {
+	out := make(chan Measurement)
+
+	s.goBackground(func(bgCtx context.Context) {
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-bgCtx.Done():
+				return
+			case _, ok := <-ready:
+				if !ok {
+					return
+				}
+			}
+
+			if s.closed() {
+				return
+			}
+
+			m, err := s.measureChecked(ctx)
+			if err == ErrDiscarded {
+				continue
+			}
+
+			if err != nil {
+				s.logError("signalled measurement failed: %s", err)
+				continue
+			}
+
+			select {
+			case out <- m:
+			case <-ctx.Done():
+				return
+			case <-bgCtx.Done():
+				return
+			}
+		}
+	})
+
+	return out
+}
@@ -0,0 +1,135 @@
+package sensor
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMeasureOnSignal(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{410, 1}, {420, 2}, {430, 3}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan struct{})
+	measurements := sensor.MeasureOnSignal(ctx, ready)
+
+	for i, expected := range []uint16{410, 420, 430} {
+		ready <- struct{}{}
+
+		m := <-measurements
+		if m.ECO2 != expected || m.TVOC != uint16(i+1) {
+			t.Error("unexpected measurement", i, m)
+		}
+	}
+
+	select {
+	case m := <-measurements:
+		t.Error("unexpected measurement without a signal", m)
+	default:
+	}
+
+	cancel()
+
+	if _, ok := <-measurements; ok {
+		t.Error("expected channel to close after cancel")
+	}
+}
+
+func TestMeasureOnSignalReadyClosed(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_mockMeasurements(sensor, [][2]uint16{{410, 1}})
+
+	ready := make(chan struct{})
+	measurements := sensor.MeasureOnSignal(context.Background(), ready)
+	close(ready)
+
+	if _, ok := <-measurements; ok {
+		t.Error("expected channel to close with ready")
+	}
+}
+
+func TestMeasureOnSignalStopsOnClose(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	mock := _mockMeasurements(sensor, [][2]uint16{{410, 1}})
+	mock.closeClosure = func() error { return nil }
+
+	writeClosure := mock.writeClosure
+	writes := 0
+	mock.writeClosure = func(buf []byte) error {
+		writes++
+		return writeClosure(buf)
+	}
+
+	ready := make(chan struct{})
+	measurements := sensor.MeasureOnSignal(context.Background(), ready)
+
+	ready <- struct{}{}
+	if m := <-measurements; m.ECO2 != 410 {
+		t.Error("unexpected measurement", m)
+	}
+
+	if err := sensor.Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	select {
+	case ready <- struct{}{}:
+		t.Error("expected the loop to have exited on Close")
+	default:
+	}
+
+	if m, ok := <-measurements; ok {
+		t.Error("expected channel to close after Close", m)
+	}
+
+	if writes != 1 {
+		t.Error("expected no reads after Close", writes)
+	}
+}
+
+func TestMeasureOnSignalDiscardsInitialReadings(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.DiscardInitialReadings = 2
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{410, 1}, {420, 2}, {430, 3}, {440, 4}})
+	sensor.startWarmup()
+
+	if _, _, err := sensor.Measure(); err != ErrDiscarded {
+		t.Error("expected first reading discarded", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		ready <- struct{}{}
+	}
+
+	measurements := sensor.MeasureOnSignal(ctx, ready)
+
+	if m := <-measurements; m.ECO2 != 430 {
+		t.Error("expected the third reading to be emitted first", m)
+	}
+
+	if m := <-measurements; m.ECO2 != 440 {
+		t.Error("unexpected measurement", m)
+	}
+
+	if _, ok := sensor.LastMeasurement(); !ok {
+		t.Error("expected emitted readings to be cached")
+	}
+
+	sensor.startWarmup()
+
+	if _, _, err := sensor.Measure(); err != ErrDiscarded {
+		t.Error("expected discarding to restart after Init", err)
+	}
+}
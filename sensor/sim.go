@@ -0,0 +1,135 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+const (
+	SimFeatureSet   uint16 = 0x0020
+	SimRawH2        uint16 = 13600
+	SimRawEthanol   uint16 = 19200
+	SimBaselineECO2 uint16 = 0x8973
+	SimBaselineTVOC uint16 = 0x8aae
+)
+
+// SimConnection is a Connection that answers like an SGP30 so the package can
+// run end-to-end without hardware. Replies carry valid CRCs and eCO2/TVOC come
+// from the generator.
+type SimConnection struct {
+	mu        sync.Mutex
+	serial    uint64
+	generator func() (eCO2, TVOC uint16)
+	baseline  Baseline
+	humidity  uint16
+	reply     []byte
+}
+
+// NewSimConnection simulates a sensor with the given 48 bit serial. A nil
+// generator reports the 400 ppm / 0 ppb warm-up values.
+func NewSimConnection(serial uint64, generator func() (eCO2, TVOC uint16)) *SimConnection {
+	if generator == nil {
+		generator = func() (uint16, uint16) {
+			return MinECO2, 0
+		}
+	}
+
+	return &SimConnection{
+		serial:    serial,
+		generator: generator,
+		baseline:  Baseline{ECO2: SimBaselineECO2, TVOC: SimBaselineTVOC},
+	}
+}
+
+// Write takes a command frame and prepares the reply for the next Read.
+// Unknown commands and arguments with bad CRCs fail like a NAK would.
+func (c *SimConnection) Write(buf []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reply = nil
+	if len(buf) < 2 || (len(buf)-2)%3 != 0 {
+		return fmt.Errorf("sim: malformed frame %x", buf)
+	}
+
+	var args []uint16
+	for i := 2; i < len(buf); i += 3 {
+		if !VerifyWordCRC(buf[i : i+3]) {
+			return fmt.Errorf("sim: bad argument crc in %x", buf)
+		}
+		args = append(args, binary.BigEndian.Uint16(buf[i:i+2]))
+	}
+
+	switch Command(binary.BigEndian.Uint16(buf)) {
+	case GetSerialID:
+		c.respond(uint16(c.serial>>32), uint16(c.serial>>16), uint16(c.serial))
+	case GetFeatureSetVersion:
+		c.respond(SimFeatureSet)
+	case InitAirQuality:
+	case MeasureAirQuality:
+		c.respond(c.generator())
+	case MeasureRawSignals:
+		c.respond(SimRawH2, SimRawEthanol)
+	case MeasureTest:
+		c.respond(SelfTestPattern)
+	case GetBaseline:
+		c.respond(c.baseline.ECO2, c.baseline.TVOC)
+	case SetBaseline:
+		if len(args) != 2 {
+			return fmt.Errorf("sim: set baseline takes 2 words, got %d", len(args))
+		}
+		c.baseline = Baseline{ECO2: args[0], TVOC: args[1]}
+	case SetHumidity:
+		if len(args) != 1 {
+			return fmt.Errorf("sim: set humidity takes 1 word, got %d", len(args))
+		}
+		c.humidity = args[0]
+	default:
+		return fmt.Errorf("sim: unknown command %x", buf[:2])
+	}
+
+	return nil
+}
+
+func (c *SimConnection) respond(words ...uint16) {
+	for _, word := range words {
+		c.reply = append(c.reply, packWordCrc(word)...)
+	}
+}
+
+// Read returns the reply to the last command. Reading more than was prepared
+// fails like a NAK would.
+func (c *SimConnection) Read(buf []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(buf) > len(c.reply) {
+		return fmt.Errorf("sim: read %d bytes, %d pending", len(buf), len(c.reply))
+	}
+
+	copy(buf, c.reply)
+	c.reply = c.reply[len(buf):]
+
+	return nil
+}
+
+func (c *SimConnection) ReadReg(reg byte, buf []byte) error {
+	return fmt.Errorf("sim: register reads are not supported")
+}
+
+func (c *SimConnection) WriteReg(reg byte, buf []byte) error {
+	return fmt.Errorf("sim: register writes are not supported")
+}
+
+func (c *SimConnection) Close() error {
+	return nil
+}
+
+// Humidity returns the last absolute humidity written with SetHumidity.
+func (c *SimConnection) Humidity() uint16 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.humidity
+}
@@ -0,0 +1,74 @@
+package sensor
+
+import "testing"
+
+func TestSimConnection(t *testing.T) {
+	readings := [][2]uint16{{412, 35}, {430, 40}}
+	next := 0
+	conn := NewSimConnection(0x010203040506, func() (uint16, uint16) {
+		reading := readings[next%len(readings)]
+		next++
+
+		return reading[0], reading[1]
+	})
+
+	sensor := NewSensorWithConnection(DefaultConfig(), conn)
+	_newFakeClock(sensor)
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected init error", err)
+	}
+
+	if sensor.SerialID != 0x010203040506 {
+		t.Errorf("unexpected serial %x", sensor.SerialID)
+	}
+
+	for _, reading := range readings {
+		eCO2, TVOC, err := sensor.Measure()
+		if err != nil {
+			t.Fatal("unexpected measure error", err)
+		}
+
+		if eCO2 != reading[0] || TVOC != reading[1] {
+			t.Error("unexpected measurement", reading, eCO2, TVOC)
+		}
+	}
+
+	eCO2, TVOC, err := sensor.GetBaseline()
+	if err != nil || eCO2 != SimBaselineECO2 || TVOC != SimBaselineTVOC {
+		t.Error("unexpected baseline", eCO2, TVOC, err)
+	}
+
+	if err := sensor.SetBaseline(0x8a00, 0x8b00); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if eCO2, TVOC, _ := sensor.GetBaseline(); eCO2 != 0x8a00 || TVOC != 0x8b00 {
+		t.Errorf("expected the set baseline back, %x %x", eCO2, TVOC)
+	}
+
+	if err := sensor.SetHumidity(15.5); err != nil || conn.Humidity() != 0x0f80 {
+		t.Error("unexpected humidity", conn.Humidity(), err)
+	}
+
+	if passed, err := sensor.SelfTest(); err != nil || !passed {
+		t.Error("expected the self test to pass", passed, err)
+	}
+
+	if _, err := sensor.SendCommand(0x1234, 1); err == nil {
+		t.Error("expected unknown commands to fail")
+	}
+
+	if err := conn.Write([]byte{0x20, 0x1e, 0x8a, 0x00, 0x00}); err == nil {
+		t.Error("expected a bad argument crc to fail")
+	}
+}
+
+func TestSimConnectionDefaultGenerator(t *testing.T) {
+	sensor := NewSensorWithConnection(DefaultConfig(), NewSimConnection(1, nil))
+	_newFakeClock(sensor)
+
+	if eCO2, TVOC, err := sensor.Measure(); err != nil || eCO2 != MinECO2 || TVOC != 0 {
+		t.Error("expected warm-up values", eCO2, TVOC, err)
+	}
+}
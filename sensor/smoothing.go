@@ -0,0 +1,36 @@
+package sensor
+
+// movingAverage keeps the last window eCO2 and TVOC values for SmoothingWindow.
+type movingAverage struct {
+	eco2  []uint16
+	tvoc  []uint16
+	next  int
+	count int
+}
+
+// add records m and returns it with eCO2 and TVOC replaced by the rounded
+// mean of the values held so far, up to window of them.
+func (a *movingAverage) add(m Measurement, window int) Measurement {
+	if len(a.eco2) != window {
+		*a = movingAverage{eco2: make([]uint16, window), tvoc: make([]uint16, window)}
+	}
+
+	a.eco2[a.next] = m.ECO2
+	a.tvoc[a.next] = m.TVOC
+	a.next = (a.next + 1) % window
+	if a.count < window {
+		a.count++
+	}
+
+	var eco2, tvoc uint64
+	for i := 0; i < a.count; i++ {
+		eco2 += uint64(a.eco2[i])
+		tvoc += uint64(a.tvoc[i])
+	}
+
+	n := uint64(a.count)
+	m.ECO2 = uint16((eco2 + n/2) / n)
+	m.TVOC = uint16((tvoc + n/2) / n)
+
+	return m
+}
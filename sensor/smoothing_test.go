@@ -0,0 +1,32 @@
+package sensor
+
+import "testing"
+
+func TestSmoothingWindow(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.SmoothingWindow = 3
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{400, 10}, {430, 20}, {460, 60}, {490, 0}})
+
+	expected := [][2]uint16{{400, 10}, {415, 15}, {430, 30}, {460, 27}}
+	for i, row := range expected {
+		eCO2, TVOC, err := sensor.Measure()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if eCO2 != row[0] || TVOC != row[1] {
+			t.Error("unexpected average", i, row, eCO2, TVOC)
+		}
+	}
+
+	if raw, _ := sensor.LastRawMeasurement(); raw.ECO2 != 490 || raw.TVOC != 0 {
+		t.Error("expected the raw reading to be kept", raw)
+	}
+
+	sensor.ResetCaches()
+
+	if eCO2, TVOC, err := sensor.Measure(); err != nil || eCO2 != 400 || TVOC != 10 {
+		t.Error("expected the window to restart after ResetCaches", eCO2, TVOC, err)
+	}
+}
@@ -0,0 +1,70 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MeasureStable measures every MeasureInterval until the last consecutive
+// readings are all within tolerance of each other and returns the latest.
+// Readings taken during warmup or discarded after Init don't count.
+func (s *SGP30Sensor) MeasureStable(ctx context.Context, tolerance uint16, consecutive int) (Measurement, error) {
+	if consecutive < 1 {
+		return Measurement{}, fmt.Errorf("consecutive must be at least 1")
+	}
+
+	readings := make([]Measurement, 0, consecutive)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return Measurement{}, err
+		}
+
+		m, err := s.measure(ctx)
+		switch {
+		case errors.Is(err, ErrDiscarded):
+			// Discarded readings don't count, keep sampling.
+		case err != nil:
+			return Measurement{}, err
+		case m.InWarmup:
+			// Warmup placeholders are identical, so they would look stable.
+			readings = readings[:0]
+		default:
+			if len(readings) == consecutive {
+				readings = readings[1:]
+			}
+			readings = append(readings, m)
+
+			if len(readings) == consecutive && readingsWithin(readings, tolerance) {
+				return readings[len(readings)-1], nil
+			}
+		}
+
+		if err := s.sleepContext(ctx, s.config().MeasureInterval); err != nil {
+			return Measurement{}, err
+		}
+	}
+}
+
+func readingsWithin(readings []Measurement, tolerance uint16) bool {
+	minECO2, maxECO2 := readings[0].ECO2, readings[0].ECO2
+	minTVOC, maxTVOC := readings[0].TVOC, readings[0].TVOC
+
+	for _, m := range readings[1:] {
+		if m.ECO2 < minECO2 {
+			minECO2 = m.ECO2
+		}
+		if m.ECO2 > maxECO2 {
+			maxECO2 = m.ECO2
+		}
+		if m.TVOC < minTVOC {
+			minTVOC = m.TVOC
+		}
+		if m.TVOC > maxTVOC {
+			maxTVOC = m.TVOC
+		}
+	}
+
+	return maxECO2-minECO2 <= tolerance && maxTVOC-minTVOC <= tolerance
+}
@@ -0,0 +1,111 @@
+package sensor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMeasureStableConverges(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	sensor.startWarmup()
+	clock.Advance(WarmupDuration)
+
+	_mockMeasurements(sensor, [][2]uint16{
+		{500, 10}, {700, 50}, {520, 12}, {522, 13}, {521, 12}, {900, 90},
+	})
+
+	m, err := sensor.MeasureStable(context.Background(), 5, 3)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if m.ECO2 != 521 || m.TVOC != 12 {
+		t.Error("unexpected measurement", m)
+	}
+
+	intervals := 0
+	for _, d := range clock.sleeps {
+		if d == time.Second {
+			intervals++
+		}
+	}
+
+	if intervals != 4 {
+		t.Error("unexpected measure interval count", 4, intervals)
+	}
+}
+
+func TestMeasureStableTimesOut(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	sensor.startWarmup()
+	clock.Advance(WarmupDuration)
+
+	_mockMeasurements(sensor, [][2]uint16{{500, 10}, {700, 50}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	intervals := 0
+	clock.onSleep = func(d time.Duration) {
+		if d == time.Second {
+			intervals++
+			if intervals == 5 {
+				cancel()
+			}
+		}
+	}
+
+	if _, err := sensor.MeasureStable(ctx, 5, 3); err != context.Canceled {
+		t.Error("expected context error", err)
+	}
+}
+
+func TestMeasureStableSkipsDiscarded(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	sensor.startWarmup()
+	sensor.cfg.DiscardInitialReadings = 2
+	clock.Advance(WarmupDuration)
+
+	_mockMeasurements(sensor, [][2]uint16{
+		{900, 90}, {100, 10}, {520, 12}, {521, 12},
+	})
+
+	m, err := sensor.MeasureStable(context.Background(), 5, 2)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if m.ECO2 != 521 || m.TVOC != 12 {
+		t.Error("unexpected measurement", m)
+	}
+}
+
+func TestMeasureStableSkipsWarmup(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+	sensor.startWarmup()
+
+	readings := make([][2]uint16, 0, 17)
+	for i := 0; i < 15; i++ {
+		readings = append(readings, [2]uint16{400, 0})
+	}
+	readings = append(readings, [2]uint16{520, 12}, [2]uint16{521, 12})
+	_mockMeasurements(sensor, readings)
+
+	m, err := sensor.MeasureStable(context.Background(), 5, 2)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if m.InWarmup || m.ECO2 != 521 || m.TVOC != 12 {
+		t.Error("expected warmup placeholders to be skipped", m)
+	}
+}
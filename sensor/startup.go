@@ -0,0 +1,47 @@
+package sensor
+
+import "context"
+
+// StartupSequence runs the datasheet's startup order in one transaction:
+// InitAirQuality, then the absolute humidity word if non-zero, then baseline
+// if non-nil. Warmup tracking restarts, and Measure can follow right away.
+func (s *SGP30Sensor) StartupSequence(baseline *Baseline, absoluteHumidity uint16) error {
+	if err := s.startupSequence(context.Background(), baseline, absoluteHumidity); err != nil {
+		return err
+	}
+
+	s.startWarmup()
+
+	if baseline != nil {
+		s.stateMu.Lock()
+		s.baselineRestored = true
+		s.stateMu.Unlock()
+	}
+
+	return nil
+}
+
+func (s *SGP30Sensor) startupSequence(ctx context.Context, baseline *Baseline, absoluteHumidity uint16) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	if err := s.transactRetry(ctx, commandFrame(InitAirQuality), nil, s.delay()); err != nil {
+		return err
+	}
+
+	if absoluteHumidity != 0 {
+		if err := s.transactRetry(ctx, CommandBytes(SetHumidity, absoluteHumidity), nil, s.delay()); err != nil {
+			return err
+		}
+
+		s.setHumidityCompensation(absoluteHumidity)
+	}
+
+	if baseline != nil {
+		if err := s.transactRetry(ctx, CommandBytes(SetBaseline, baseline.ECO2, baseline.TVOC), nil, s.delay()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestStartupSequence(t *testing.T) {
+	table := []struct {
+		baseline *Baseline
+		humidity uint16
+		expected []Command
+	}{
+		{&Baseline{ECO2: 0x8a00, TVOC: 0x8b00}, 0x0f80, []Command{InitAirQuality, SetHumidity, SetBaseline}},
+		{nil, 0x0f80, []Command{InitAirQuality, SetHumidity}},
+		{&Baseline{ECO2: 0x8a00, TVOC: 0x8b00}, 0, []Command{InitAirQuality, SetBaseline}},
+		{nil, 0, []Command{InitAirQuality}},
+	}
+
+	for _, row := range table {
+		sensor := NewSensor(DefaultConfig())
+		sensor.cfg.Delay = 0
+		_newFakeClock(sensor)
+
+		var written [][]byte
+		sensor.i2cConnection = &_mockI2cConnection{
+			writeClosure: func(buf []byte) error {
+				written = append(written, buf)
+				return nil
+			},
+		}
+
+		if err := sensor.StartupSequence(row.baseline, row.humidity); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if len(written) != len(row.expected) {
+			t.Fatal("unexpected command count", row.expected, len(written))
+		}
+
+		for i, cmd := range row.expected {
+			if Command(binary.BigEndian.Uint16(written[i])) != cmd {
+				t.Errorf("unexpected command %d, %x, %x", i, cmd, written[i])
+			}
+		}
+
+		if row.humidity != 0 && !_bytesMatch(written[1], CommandBytes(SetHumidity, row.humidity)) {
+			t.Errorf("unexpected humidity frame, %x", written[1])
+		}
+
+		if row.baseline != nil && !_bytesMatch(written[len(written)-1], CommandBytes(SetBaseline, row.baseline.ECO2, row.baseline.TVOC)) {
+			t.Errorf("unexpected baseline frame, %x", written[len(written)-1])
+		}
+
+		if sensor.State() != Warmup || sensor.baselineRestored != (row.baseline != nil) {
+			t.Error("unexpected state after startup", sensor.State(), sensor.baselineRestored)
+		}
+	}
+}
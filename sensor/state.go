@@ -0,0 +1,214 @@
+package sensor
+
+import (
+	"fmt"
+	"time"
+)
+
+type State int
+
+const (
+	Disconnected State = iota
+	Connecting
+	Warmup
+	Running
+	Degraded
+	Closed
+)
+
+const (
+	WarmupDuration      = 15 * time.Second
+	DegradedErrorStreak = 3
+)
+
+func (st State) String() string {
+	switch st {
+	case Disconnected:
+		return "disconnected"
+	case Connecting:
+		return "connecting"
+	case Warmup:
+		return "warmup"
+	case Running:
+		return "running"
+	case Degraded:
+		return "degraded"
+	case Closed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// Name is the configured Config.Name, empty if unset.
+func (s *SGP30Sensor) Name() string {
+	return s.config().Name
+}
+
+// String identifies the sensor for logs by name, serial and connection state.
+func (s *SGP30Sensor) String() string {
+	if name := s.Name(); name != "" {
+		return fmt.Sprintf("SGP30 %q serial=%012x state=%s", name, s.SerialID, s.State())
+	}
+
+	return fmt.Sprintf("SGP30 serial=%012x state=%s", s.SerialID, s.State())
+}
+
+func (s *SGP30Sensor) State() State {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	return s.state
+}
+
+// closed lets background loops stop cleanly once Close has been called.
+func (s *SGP30Sensor) closed() bool {
+	return s.State() == Closed
+}
+
+func (s *SGP30Sensor) setState(state State) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.state = state
+}
+
+func (s *SGP30Sensor) startWarmup() {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	s.initTime = s.clock.Now()
+	s.firstValidTime = time.Time{}
+	s.measureErrors = 0
+	s.baselineRestored = false
+	s.warmupReadings = 0
+	s.ready = false
+	s.readyDegraded = false
+	s.discarded = 0
+	s.state = Warmup
+}
+
+// trackMeasureResult returns true when a measurement completes warmup.
+func (s *SGP30Sensor) trackMeasureResult(err error) (warmedUp bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if err != nil {
+		s.measureErrors++
+		if s.measureErrors >= DegradedErrorStreak && (s.state == Warmup || s.state == Running) {
+			s.state = Degraded
+		}
+
+		return false
+	}
+
+	s.measureErrors = 0
+
+	if s.state != Warmup && s.state != Degraded {
+		return false
+	}
+
+	if s.clock.Now().Sub(s.initTime) < WarmupDuration {
+		s.state = Warmup
+		return false
+	}
+
+	warmedUp = s.state == Warmup
+	s.state = Running
+
+	return warmedUp
+}
+
+// IsWarmedUp reports whether WarmupDuration has passed since Init, after
+// which readings are no longer placeholders.
+func (s *SGP30Sensor) IsWarmedUp() bool {
+	return s.warmedUpAt(s.clock.Now())
+}
+
+func (s *SGP30Sensor) warmedUpAt(t time.Time) bool {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	return !s.initTime.IsZero() && t.Sub(s.initTime) >= WarmupDuration
+}
+
+// TimeToFirstValid returns how long after Init the first plausible
+// post-warmup reading arrived, and whether one has arrived yet.
+func (s *SGP30Sensor) TimeToFirstValid() (time.Duration, bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if s.firstValidTime.IsZero() {
+		return 0, false
+	}
+
+	return s.firstValidTime.Sub(s.initTime), true
+}
+
+func (s *SGP30Sensor) trackFirstValid(m Measurement) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if !s.firstValidTime.IsZero() || s.initTime.IsZero() || s.state != Running || !m.Plausible() {
+		return
+	}
+
+	s.firstValidTime = m.Timestamp
+}
+
+// Ready reports whether warmup has elapsed and the placeholder 400 ppm / 0 ppb
+// readings have stopped. If they persist past MaxWarmupMeasurements readings
+// it gives up waiting and reports ready but degraded.
+func (s *SGP30Sensor) Ready() (ready bool, degraded bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	return s.ready, s.readyDegraded
+}
+
+func (s *SGP30Sensor) trackReadiness(m Measurement, cfg *Config) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if s.ready || s.initTime.IsZero() {
+		return
+	}
+
+	s.warmupReadings++
+
+	if s.clock.Now().Sub(s.initTime) >= WarmupDuration && !m.placeholder() {
+		s.ready = true
+		return
+	}
+
+	if cfg.MaxWarmupMeasurements > 0 && s.warmupReadings >= cfg.MaxWarmupMeasurements {
+		s.logError("placeholder readings persisted for %d measurements", s.warmupReadings)
+		s.ready = true
+		s.readyDegraded = true
+	}
+}
+
+func (s *SGP30Sensor) discardInitial(cfg *Config) bool {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if s.discarded >= cfg.DiscardInitialReadings {
+		return false
+	}
+
+	s.discarded++
+
+	return true
+}
+
+// WarmReset restarts the air quality algorithm and warmup tracking without
+// re-reading the serial and feature set, which never change.
+func (s *SGP30Sensor) WarmReset() error {
+	if _, err := s.readWordsUint(InitAirQuality, 0); err != nil {
+		return err
+	}
+
+	s.startWarmup()
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStateTransitions(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+
+	if sensor.State() != Disconnected {
+		t.Error("unexpected initial state", sensor.State())
+	}
+
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+		MeasureAirQuality:    {400, 0},
+	})
+	writeClosure := mock.writeClosure
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if sensor.State() != Warmup {
+		t.Error("expected warmup state", sensor.State())
+	}
+
+	if _, _, err := sensor.Measure(); err != nil || sensor.State() != Warmup {
+		t.Error("expected to remain in warmup", err, sensor.State())
+	}
+
+	clock.Advance(WarmupDuration)
+
+	if _, _, err := sensor.Measure(); err != nil || sensor.State() != Running {
+		t.Error("expected running state", err, sensor.State())
+	}
+
+	mock.writeClosure = func(buf []byte) error {
+		return fmt.Errorf("bus error")
+	}
+
+	for i := 0; i < DegradedErrorStreak; i++ {
+		if sensor.State() != Running {
+			t.Error("expected running state before error streak", sensor.State())
+		}
+
+		if _, _, err := sensor.Measure(); err == nil {
+			t.Error("expected error")
+		}
+	}
+
+	if sensor.State() != Degraded {
+		t.Error("expected degraded state", sensor.State())
+	}
+
+	mock.writeClosure = writeClosure
+
+	if _, _, err := sensor.Measure(); err != nil || sensor.State() != Running {
+		t.Error("expected recovery to running state", err, sensor.State())
+	}
+
+	mock.closeClosure = func() error {
+		return nil
+	}
+
+	if err := sensor.Close(); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if sensor.State() != Closed {
+		t.Error("expected closed state", sensor.State())
+	}
+}
+
+func TestInitFailureState(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.i2cConnection = &_mockI2cConnection{
+		writeClosure: func(buf []byte) error {
+			return fmt.Errorf("bus error")
+		},
+	}
+
+	if err := sensor.Init(); err == nil {
+		t.Error("expected error")
+	}
+
+	if sensor.State() != Disconnected {
+		t.Error("expected disconnected state", sensor.State())
+	}
+}
+
+func TestReconnectState(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	_newFakeClock(sensor)
+	sensor.i2cConnection = &_mockI2cConnection{closeClosure: func() error { return nil }}
+	sensor.setState(Running)
+
+	var openErr error
+	sensor.opener = &_mockOpener{
+		statClosure: func(name string) (os.FileInfo, error) {
+			return nil, nil
+		},
+		openClosure: func(path string, addr byte) (Connection, error) {
+			if sensor.State() != Connecting {
+				t.Error("expected connecting state while opening", sensor.State())
+			}
+
+			return &_mockI2cConnection{closeClosure: func() error { return nil }}, openErr
+		},
+	}
+
+	if err := sensor.Reconnect(); err != nil || sensor.State() != Running {
+		t.Error("expected the previous state after reconnecting", err, sensor.State())
+	}
+
+	openErr = fmt.Errorf("no such device")
+
+	if err := sensor.Reconnect(); err == nil || sensor.State() != Disconnected {
+		t.Error("expected disconnected state after a failed reconnect", err, sensor.State())
+	}
+}
+
+func TestTimeToFirstValid(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	clock := _newFakeClock(sensor)
+	responses := map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+		MeasureAirQuality:    {400, 0},
+	}
+	_mockResponses(sensor, responses)
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	sensor.Measure()
+	if _, ok := sensor.TimeToFirstValid(); ok {
+		t.Error("expected no valid reading during warmup")
+	}
+
+	clock.Advance(WarmupDuration + time.Second)
+	responses[MeasureAirQuality] = []uint16{0, 0}
+	sensor.Measure()
+	if _, ok := sensor.TimeToFirstValid(); ok {
+		t.Error("expected implausible reading to be ignored")
+	}
+
+	clock.Advance(time.Second)
+	responses[MeasureAirQuality] = []uint16{412, 35}
+	sensor.Measure()
+
+	clock.Advance(time.Second)
+	sensor.Measure()
+
+	elapsed, ok := sensor.TimeToFirstValid()
+	if !ok || elapsed != WarmupDuration+2*time.Second {
+		t.Error("unexpected time to first valid", elapsed, ok)
+	}
+}
+
+func TestWarmReset(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	mock := _mockResponses(sensor, map[Command][]uint16{
+		GetSerialID:          {0x0102, 0x0304, 0x0506},
+		GetFeatureSetVersion: {0x0020},
+		MeasureAirQuality:    {412, 35},
+	})
+
+	if err := sensor.Init(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	clock.Advance(WarmupDuration)
+	sensor.Measure()
+	if sensor.State() != Running {
+		t.Fatal("expected running state", sensor.State())
+	}
+
+	writeClosure := mock.writeClosure
+	var commands []Command
+	mock.writeClosure = func(buf []byte) error {
+		commands = append(commands, Command(binary.BigEndian.Uint16(buf)))
+		return writeClosure(buf)
+	}
+
+	if err := sensor.WarmReset(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(commands) != 1 || commands[0] != InitAirQuality {
+		t.Error("expected only InitAirQuality to be written", commands)
+	}
+
+	if sensor.SerialID != 0x010203040506 || sensor.featureSet.ProductVersion != 0x20 {
+		t.Error("expected cached serial and feature set to be kept")
+	}
+
+	if sensor.State() != Warmup {
+		t.Error("expected warmup to restart", sensor.State())
+	}
+}
+
+func TestReadyWhenPlaceholdersClear(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{400, 0}, {400, 0}, {400, 0}, {412, 3}})
+	sensor.startWarmup()
+
+	if ready, _ := sensor.Ready(); ready {
+		t.Error("expected not ready before measuring")
+	}
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	clock.Advance(WarmupDuration)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if ready, _ := sensor.Ready(); ready {
+			t.Error("expected not ready while placeholders persist")
+		}
+	}
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if ready, degraded := sensor.Ready(); !ready || degraded {
+		t.Error("expected ready and not degraded", ready, degraded)
+	}
+
+	sensor.startWarmup()
+
+	if ready, _ := sensor.Ready(); ready {
+		t.Error("expected warmup to reset readiness")
+	}
+}
+
+func TestReadyBeforeWarmupElapses(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 3}})
+	sensor.startWarmup()
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if ready, _ := sensor.Ready(); ready {
+		t.Error("expected not ready before warmup elapses")
+	}
+}
+
+func TestReadyDegradedPastWarmupCap(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.MaxWarmupMeasurements = 5
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{400, 0}})
+	sensor.startWarmup()
+	clock.Advance(WarmupDuration)
+
+	for i := 0; i < 4; i++ {
+		if _, _, err := sensor.Measure(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if ready, _ := sensor.Ready(); ready {
+			t.Error("expected not ready before the cap", i)
+		}
+	}
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if ready, degraded := sensor.Ready(); !ready || !degraded {
+		t.Error("expected degraded readiness past the cap", ready, degraded)
+	}
+}
+
+func TestIsWarmedUp(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{400, 0}})
+
+	if sensor.IsWarmedUp() {
+		t.Error("expected not warmed up before Init")
+	}
+
+	table := []struct {
+		initOffset time.Duration
+		warmedUp   bool
+	}{
+		{-WarmupDuration - time.Second, true},
+		{-WarmupDuration, true},
+		{-WarmupDuration + time.Second, false},
+		{time.Second, false},
+	}
+
+	for _, row := range table {
+		sensor.stateMu.Lock()
+		sensor.initTime = clock.Now().Add(row.initOffset)
+		sensor.stateMu.Unlock()
+
+		if sensor.IsWarmedUp() != row.warmedUp {
+			t.Error("unexpected warmup state", row.initOffset, row.warmedUp)
+		}
+
+		m, err := sensor.MeasureNow()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if m.InWarmup == row.warmedUp {
+			t.Error("unexpected warmup flag on measurement", row.initOffset, m.InWarmup)
+		}
+	}
+}
+
+func TestSensorString(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.SerialID = 0x010203040506
+
+	if sensor.String() != "SGP30 serial=010203040506 state=disconnected" {
+		t.Error("unexpected string", sensor.String())
+	}
+
+	sensor.cfg.Name = "living-room"
+	sensor.setState(Running)
+
+	if sensor.String() != `SGP30 "living-room" serial=010203040506 state=running` {
+		t.Error("unexpected string", sensor.String())
+	}
+}
@@ -0,0 +1,66 @@
+package sensor
+
+import (
+	"context"
+	"time"
+)
+
+// StartMeasuring measures every interval until ctx is cancelled or the sensor
+// is closed, then closes both channels. The on-chip baseline algorithm expects
+// a steady 1Hz, so the next reading is scheduled from the previous start
+// rather than after the previous reading finished. Both channels must be
+// drained or the loop stalls.
+func (s *SGP30Sensor) StartMeasuring(ctx context.Context, interval time.Duration) (<-chan Measurement, <-chan error) {
+	out := make(chan Measurement)
+	errs := make(chan error)
+
+	s.goBackground(func(bgCtx context.Context) {
+		defer close(out)
+		defer close(errs)
+
+		// Close cancels bgCtx, which must also cut short the wait between
+		// readings.
+		ctx, cancel := mergeContext(ctx, bgCtx)
+		defer cancel()
+
+		next := s.clock.Now()
+		for {
+			if wait := next.Sub(s.clock.Now()); wait > 0 {
+				if err := s.sleepContext(ctx, wait); err != nil {
+					return
+				}
+			}
+			next = next.Add(interval)
+
+			if ctx.Err() != nil || s.closed() {
+				return
+			}
+
+			m, err := s.measureChecked(ctx)
+			if err == ErrDiscarded {
+				continue
+			}
+
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+
+			var send chan Measurement
+			var sendErr chan error
+			if err != nil {
+				sendErr = errs
+			} else {
+				send = out
+			}
+
+			select {
+			case send <- m:
+			case sendErr <- err:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	return out, errs
+}
@@ -0,0 +1,118 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartMeasuring(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	clock := _newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{410, 1}, {420, 2}, {430, 3}})
+
+	readClosure := mock.readClosure
+	reads := 0
+	mock.readClosure = func(buf []byte) error {
+		reads++
+		if reads == 2 {
+			return errors.New("bus fault")
+		}
+
+		return readClosure(buf)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := clock.Now()
+	measurements, errs := sensor.StartMeasuring(ctx, time.Second)
+
+	m := <-measurements
+	if m.ECO2 != 410 || !m.Timestamp.Equal(start) {
+		t.Error("unexpected first measurement", m)
+	}
+
+	if err := <-errs; err == nil || err.Error() != "bus fault" {
+		t.Error("expected the read error", err)
+	}
+
+	for i, expected := range []uint16{430, 410} {
+		m := <-measurements
+		if m.ECO2 != expected {
+			t.Error("unexpected measurement", i, m)
+		}
+
+		if elapsed := m.Timestamp.Sub(start); elapsed != time.Duration(i+2)*time.Second {
+			t.Error("expected a reading every second", i, elapsed)
+		}
+	}
+
+	cancel()
+
+	for range measurements {
+	}
+
+	if _, ok := <-errs; ok {
+		t.Error("expected errors to close after cancel")
+	}
+}
+
+func TestStartMeasuringStopsOnClose(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	_newFakeClock(sensor)
+	mock := _mockMeasurements(sensor, [][2]uint16{{410, 1}})
+	mock.closeClosure = func() error { return nil }
+
+	measurements, errs := sensor.StartMeasuring(context.Background(), time.Second)
+	<-measurements
+
+	if err := sensor.Close(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, ok := <-measurements; ok {
+		t.Error("expected measurements to close after Close")
+	}
+
+	if _, ok := <-errs; ok {
+		t.Error("expected errors to close after Close")
+	}
+}
+
+func TestStartMeasuringCloseInterruptsWait(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.CommandDelays = nil
+	mock := _mockMeasurements(sensor, [][2]uint16{{410, 1}})
+	mock.closeClosure = func() error { return nil }
+
+	measurements, errs := sensor.StartMeasuring(context.Background(), time.Hour)
+	<-measurements
+
+	closed := make(chan error)
+	go func() {
+		closed <- sensor.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Close to interrupt the wait for the next reading")
+	}
+
+	if _, ok := <-measurements; ok {
+		t.Error("expected measurements to close after Close")
+	}
+
+	if _, ok := <-errs; ok {
+		t.Error("expected errors to close after Close")
+	}
+}
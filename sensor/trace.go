@@ -0,0 +1,212 @@
+package sensor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	traceWrite = "W"
+	traceRead  = "R"
+)
+
+type traceOp struct {
+	time time.Time
+	kind string
+	data []byte
+}
+
+// traceConnection copies bus traffic to w. A failed trace write is logged
+// rather than returned, so it never looks like a bus error.
+type traceConnection struct {
+	conn   Connection
+	w      io.Writer
+	sensor *SGP30Sensor
+}
+
+func (t *traceConnection) Read(buf []byte) error {
+	if err := t.conn.Read(buf); err != nil {
+		return err
+	}
+
+	t.record(traceRead, buf)
+
+	return nil
+}
+
+func (t *traceConnection) ReadReg(reg byte, buf []byte) error {
+	return t.conn.ReadReg(reg, buf)
+}
+
+func (t *traceConnection) Write(buf []byte) error {
+	if err := t.conn.Write(buf); err != nil {
+		return err
+	}
+
+	t.record(traceWrite, buf)
+
+	return nil
+}
+
+func (t *traceConnection) WriteReg(reg byte, buf []byte) (err error) {
+	return t.conn.WriteReg(reg, buf)
+}
+
+func (t *traceConnection) Close() error {
+	return t.conn.Close()
+}
+
+func (t *traceConnection) record(kind string, buf []byte) {
+	if _, err := fmt.Fprintf(t.w, "%s %s %x\n", t.sensor.clock.Now().Format(time.RFC3339Nano), kind, buf); err != nil {
+		t.sensor.logError("failed to write trace: %s", err)
+	}
+}
+
+// traced wraps conn to write Config.Trace when one is set.
+func (s *SGP30Sensor) traced(conn Connection) Connection {
+	if w := s.config().Trace; w != nil {
+		return &traceConnection{conn: conn, w: w, sensor: s}
+	}
+
+	return conn
+}
+
+// ReplayTrace decodes the air quality measurements in a trace written via
+// Config.Trace, checking every reply's CRC as a live sensor would.
+func ReplayTrace(r io.Reader) ([]Measurement, error) {
+	ops, err := readTrace(r)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &replayConnection{ops: ops}
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	sensor := NewSensor(cfg)
+	sensor.clock = replay
+	sensor.i2cConnection = replay
+
+	var measurements []Measurement
+
+	for replay.pos < len(ops) {
+		op := ops[replay.pos]
+		if op.kind != traceWrite || len(op.data) < 2 || Command(binary.BigEndian.Uint16(op.data)) != MeasureAirQuality {
+			replay.pos++
+			continue
+		}
+
+		m, err := sensor.measure(context.Background())
+		if err != nil {
+			return measurements, fmt.Errorf("trace line %d: %w", replay.pos+1, err)
+		}
+
+		measurements = append(measurements, m)
+	}
+
+	return measurements, nil
+}
+
+func readTrace(r io.Reader) ([]traceOp, error) {
+	var ops []traceOp
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 3 || (fields[1] != traceWrite && fields[1] != traceRead) {
+			return nil, fmt.Errorf("malformed trace line %d", line)
+		}
+
+		timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("trace line %d: %w", line, err)
+		}
+
+		data, err := hex.DecodeString(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("trace line %d: %w", line, err)
+		}
+
+		ops = append(ops, traceOp{time: timestamp, kind: fields[1], data: data})
+	}
+
+	return ops, scanner.Err()
+}
+
+type replayConnection struct {
+	ops []traceOp
+	pos int
+	now time.Time
+}
+
+func (c *replayConnection) next(kind string) (traceOp, error) {
+	if c.pos >= len(c.ops) {
+		return traceOp{}, fmt.Errorf("trace ended")
+	}
+
+	op := c.ops[c.pos]
+	if op.kind != kind {
+		return traceOp{}, fmt.Errorf("expected %s on trace line %d", kind, c.pos+1)
+	}
+
+	c.pos++
+	c.now = op.time
+
+	return op, nil
+}
+
+func (c *replayConnection) Read(buf []byte) error {
+	op, err := c.next(traceRead)
+	if err != nil {
+		return err
+	}
+
+	if len(op.data) != len(buf) {
+		return fmt.Errorf("trace line %d has %d bytes, expected %d", c.pos, len(op.data), len(buf))
+	}
+
+	copy(buf, op.data)
+
+	return nil
+}
+
+func (c *replayConnection) ReadReg(reg byte, buf []byte) error {
+	return fmt.Errorf("register reads are not traced")
+}
+
+func (c *replayConnection) Write(buf []byte) error {
+	op, err := c.next(traceWrite)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(op.data, buf) {
+		return fmt.Errorf("trace line %d wrote %x, expected %x", c.pos, op.data, buf)
+	}
+
+	return nil
+}
+
+func (c *replayConnection) WriteReg(reg byte, buf []byte) (err error) {
+	return fmt.Errorf("register writes are not traced")
+}
+
+func (c *replayConnection) Close() error {
+	return nil
+}
+
+func (c *replayConnection) Now() time.Time {
+	return c.now
+}
+
+func (c *replayConnection) Sleep(d time.Duration) {}
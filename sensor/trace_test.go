@@ -0,0 +1,96 @@
+package sensor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplayTrace(t *testing.T) {
+	mock := _mockResponses(NewSensor(DefaultConfig()), map[Command][]uint16{
+		MeasureAirQuality: {412, 35},
+		GetBaseline:       {0x8973, 0x8aae},
+	})
+
+	trace := &bytes.Buffer{}
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.Trace = trace
+	sensor := NewSensorWithConnection(cfg, mock)
+	clock := _newFakeClock(sensor)
+
+	expected := []Measurement{}
+	for i := 0; i < 2; i++ {
+		m, err := sensor.measure(context.Background())
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		expected = append(expected, m)
+
+		if _, _, err := sensor.GetBaseline(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		clock.Advance(time.Second)
+	}
+
+	measurements, err := ReplayTrace(trace)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(measurements) != len(expected) {
+		t.Fatal("unexpected measurement count", len(expected), len(measurements))
+	}
+
+	for i := range expected {
+		if measurements[i] != expected[i] {
+			t.Error("mismatched measurement", expected[i], measurements[i])
+		}
+	}
+}
+
+func TestTraceWriteErrorLogged(t *testing.T) {
+	logger := &_captureLogger{}
+	mock := _mockMeasurements(NewSensor(DefaultConfig()), [][2]uint16{{412, 35}})
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.Logger = logger
+	cfg.Trace = _failingWriter{}
+	sensor := NewSensorWithConnection(cfg, mock)
+	_newFakeClock(sensor)
+
+	if _, _, err := sensor.Measure(); err != nil {
+		t.Fatal("expected a trace failure not to fail the measurement", err)
+	}
+
+	if len(logger.lines) != 2 || logger.lines[0] != "ERROR failed to write trace: disk full" {
+		t.Error("expected trace failures to be logged", logger.lines)
+	}
+}
+
+type _failingWriter struct{}
+
+func (_failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestReplayTraceCrcMismatch(t *testing.T) {
+	trace := strings.Join([]string{
+		"2020-01-01T00:00:00Z W 2008",
+		"2020-01-01T00:00:00Z R 019c00002381",
+	}, "\n")
+
+	_, err := ReplayTrace(strings.NewReader(trace))
+	if err == nil || !strings.Contains(err.Error(), "crc mismatch") {
+		t.Error("expected crc error", err)
+	}
+
+	var crcErr *CRCError
+	if !IsCRCError(err) || !errors.As(err, &crcErr) || crcErr.WordIndex != 0 {
+		t.Error("expected the crc error to be unwrappable", err)
+	}
+}
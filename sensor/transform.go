@@ -0,0 +1,91 @@
+package sensor
+
+import (
+	"math"
+	"sync"
+)
+
+// AdaptiveSmoothTransform returns a PostProcess func that smooths eCO2 and
+// TVOC with an exponentially weighted moving average, but jumps straight to
+// the raw value when a reading moves more than stepThreshold from the
+// smoothed one so real events like opening a window aren't damped. The
+// returned func is safe to share between goroutines.
+func AdaptiveSmoothTransform(alpha float64, stepThreshold uint16) func(Measurement) Measurement {
+	var mu sync.Mutex
+	var eco2, tvoc float64
+	started := false
+
+	return func(m Measurement) Measurement {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !started {
+			eco2, tvoc = float64(m.ECO2), float64(m.TVOC)
+			started = true
+
+			return m
+		}
+
+		eco2 = adaptiveSmooth(eco2, m.ECO2, alpha, stepThreshold)
+		tvoc = adaptiveSmooth(tvoc, m.TVOC, alpha, stepThreshold)
+
+		m.ECO2 = uint16(math.Round(eco2))
+		m.TVOC = uint16(math.Round(tvoc))
+
+		return m
+	}
+}
+
+func adaptiveSmooth(smoothed float64, raw uint16, alpha float64, stepThreshold uint16) float64 {
+	if math.Abs(float64(raw)-smoothed) > float64(stepThreshold) {
+		return float64(raw)
+	}
+
+	return smoothed + alpha*(float64(raw)-smoothed)
+}
+
+// RateLimitTransform returns a PostProcess func that clamps eCO2 and TVOC so
+// they never change faster than the given per-second limits since the previous
+// reading, which filters out glitches no real air could produce. The returned
+// func is safe to share between goroutines.
+func RateLimitTransform(maxECO2PerSecond, maxTVOCPerSecond uint16) func(Measurement) Measurement {
+	var mu sync.Mutex
+	var prev Measurement
+	started := false
+
+	return func(m Measurement) Measurement {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !started {
+			prev = m
+			started = true
+
+			return m
+		}
+
+		elapsed := m.Timestamp.Sub(prev.Timestamp).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+
+		m.ECO2 = rateLimit(prev.ECO2, m.ECO2, float64(maxECO2PerSecond)*elapsed)
+		m.TVOC = rateLimit(prev.TVOC, m.TVOC, float64(maxTVOCPerSecond)*elapsed)
+		prev = m
+
+		return m
+	}
+}
+
+func rateLimit(prev, value uint16, maxChange float64) uint16 {
+	change := float64(value) - float64(prev)
+	if math.Abs(change) <= maxChange {
+		return value
+	}
+
+	if change > 0 {
+		return uint16(float64(prev) + maxChange)
+	}
+
+	return uint16(float64(prev) - maxChange)
+}
@@ -0,0 +1,114 @@
+package sensor
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveSmoothTransform(t *testing.T) {
+	transform := AdaptiveSmoothTransform(0.5, 100)
+
+	table := []struct {
+		raw      [2]uint16
+		expected [2]uint16
+	}{
+		{[2]uint16{400, 10}, [2]uint16{400, 10}},
+		{[2]uint16{420, 14}, [2]uint16{410, 12}},
+		{[2]uint16{440, 18}, [2]uint16{425, 15}},
+		{[2]uint16{460, 22}, [2]uint16{443, 19}},
+		{[2]uint16{900, 24}, [2]uint16{900, 21}},
+		{[2]uint16{910, 24}, [2]uint16{905, 23}},
+	}
+
+	for i, row := range table {
+		m := transform(Measurement{ECO2: row.raw[0], TVOC: row.raw[1]})
+		if m.ECO2 != row.expected[0] || m.TVOC != row.expected[1] {
+			t.Error("unexpected smoothing", i, row.expected, m)
+		}
+	}
+}
+
+func TestAdaptiveSmoothTransformConcurrent(t *testing.T) {
+	transform := AdaptiveSmoothTransform(0.5, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				if m := transform(Measurement{ECO2: 412, TVOC: 35}); m.ECO2 != 412 || m.TVOC != 35 {
+					t.Error("unexpected smoothing", m)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestAdaptiveSmoothTransformAsPostProcess(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	sensor.cfg.PostProcess = AdaptiveSmoothTransform(0.5, 100)
+	_mockMeasurements(sensor, [][2]uint16{{400, 10}, {420, 14}, {900, 14}})
+
+	for _, expected := range []uint16{400, 410, 900} {
+		eCO2, _, err := sensor.Measure()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if eCO2 != expected {
+			t.Error("unexpected eCO2", expected, eCO2)
+		}
+	}
+}
+
+func TestRateLimitTransform(t *testing.T) {
+	transform := RateLimitTransform(100, 50)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	table := []struct {
+		offset   time.Duration
+		raw      [2]uint16
+		expected [2]uint16
+	}{
+		{0, [2]uint16{400, 10}, [2]uint16{400, 10}},
+		{time.Second, [2]uint16{450, 40}, [2]uint16{450, 40}},
+		{2 * time.Second, [2]uint16{40000, 5000}, [2]uint16{550, 90}},
+		{4 * time.Second, [2]uint16{40000, 5000}, [2]uint16{750, 190}},
+		{5 * time.Second, [2]uint16{400, 0}, [2]uint16{650, 140}},
+		{5 * time.Second, [2]uint16{700, 150}, [2]uint16{650, 140}},
+	}
+
+	for i, row := range table {
+		m := transform(Measurement{ECO2: row.raw[0], TVOC: row.raw[1], Timestamp: start.Add(row.offset)})
+		if m.ECO2 != row.expected[0] || m.TVOC != row.expected[1] {
+			t.Error("unexpected rate limiting", i, row.expected, m)
+		}
+	}
+}
+
+func TestRateLimitTransformConcurrent(t *testing.T) {
+	transform := RateLimitTransform(100, 50)
+	timestamp := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				if m := transform(Measurement{ECO2: 412, TVOC: 35, Timestamp: timestamp}); m.ECO2 != 412 || m.TVOC != 35 {
+					t.Error("unexpected rate limiting", m)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
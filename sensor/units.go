@@ -0,0 +1,51 @@
+package sensor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type ECO2 uint16
+
+type TVOC uint16
+
+type TypedMeasurement struct {
+	ECO2      ECO2
+	TVOC      TVOC
+	Timestamp time.Time
+}
+
+func (e ECO2) PPM() uint16 {
+	return uint16(e)
+}
+
+func (e ECO2) String() string {
+	return fmt.Sprintf("%dppm", uint16(e))
+}
+
+func (v TVOC) PPB() uint16 {
+	return uint16(v)
+}
+
+func (v TVOC) String() string {
+	return fmt.Sprintf("%dppb", uint16(v))
+}
+
+// String renders the reading for logs, e.g. "eCO2=412ppm TVOC=35ppb @ 15:04:05".
+func (m Measurement) String() string {
+	return fmt.Sprintf("eCO2=%s TVOC=%s @ %s", ECO2(m.ECO2), TVOC(m.TVOC), m.Timestamp.Format("15:04:05"))
+}
+
+func (m Measurement) Typed() TypedMeasurement {
+	return TypedMeasurement{ECO2: ECO2(m.ECO2), TVOC: TVOC(m.TVOC), Timestamp: m.Timestamp}
+}
+
+func (s *SGP30Sensor) MeasureTyped() (TypedMeasurement, error) {
+	m, err := s.measureChecked(context.Background())
+	if err != nil {
+		return TypedMeasurement{}, err
+	}
+
+	return m.Typed(), nil
+}
@@ -0,0 +1,43 @@
+package sensor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnitTypes(t *testing.T) {
+	eCO2 := ECO2(412)
+	if eCO2.PPM() != 412 || eCO2.String() != "412ppm" {
+		t.Error("unexpected eCO2", eCO2.PPM(), eCO2.String())
+	}
+
+	tvoc := TVOC(35)
+	if tvoc.PPB() != 35 || tvoc.String() != "35ppb" {
+		t.Error("unexpected TVOC", tvoc.PPB(), tvoc.String())
+	}
+}
+
+func TestMeasureTyped(t *testing.T) {
+	sensor := NewSensor(DefaultConfig())
+	sensor.cfg.Delay = 0
+	clock := _newFakeClock(sensor)
+	_mockMeasurements(sensor, [][2]uint16{{412, 35}})
+
+	m, err := sensor.MeasureTyped()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := TypedMeasurement{ECO2: 412, TVOC: 35, Timestamp: clock.Now()}
+	if m != expected {
+		t.Error("unexpected measurement", expected, m)
+	}
+}
+
+func TestMeasurementString(t *testing.T) {
+	m := Measurement{ECO2: 412, TVOC: 35, Timestamp: time.Date(2020, 1, 1, 15, 4, 5, 0, time.UTC)}
+
+	if m.String() != "eCO2=412ppm TVOC=35ppb @ 15:04:05" {
+		t.Error("unexpected string", m.String())
+	}
+}
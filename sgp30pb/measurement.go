@@ -0,0 +1,19 @@
+// Package sgp30pb maps sensor measurements onto the message in
+// measurement.proto so the sensor package stays free of protobuf.
+package sgp30pb
+
+import (
+	"github.com/ataboo/sgp30go/sensor"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative measurement.proto
+
+func ToProto(m sensor.Measurement) *Measurement {
+	return &Measurement{
+		Serial:  m.SerialID,
+		Eco2Ppm: uint32(m.ECO2),
+		TvocPpb: uint32(m.TVOC),
+		Time:    timestamppb.New(m.Timestamp),
+	}
+}
@@ -0,0 +1,184 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        (unknown)
+// source: measurement.proto
+
+package sgp30pb
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type Measurement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Serial  uint64               `protobuf:"varint,1,opt,name=serial,proto3" json:"serial,omitempty"`
+	Eco2Ppm uint32               `protobuf:"varint,2,opt,name=eco2_ppm,json=eco2Ppm,proto3" json:"eco2_ppm,omitempty"`
+	TvocPpb uint32               `protobuf:"varint,3,opt,name=tvoc_ppb,json=tvocPpb,proto3" json:"tvoc_ppb,omitempty"`
+	Time    *timestamp.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (x *Measurement) Reset() {
+	*x = Measurement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_measurement_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Measurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Measurement) ProtoMessage() {}
+
+func (x *Measurement) ProtoReflect() protoreflect.Message {
+	mi := &file_measurement_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Measurement.ProtoReflect.Descriptor instead.
+func (*Measurement) Descriptor() ([]byte, []int) {
+	return file_measurement_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Measurement) GetSerial() uint64 {
+	if x != nil {
+		return x.Serial
+	}
+	return 0
+}
+
+func (x *Measurement) GetEco2Ppm() uint32 {
+	if x != nil {
+		return x.Eco2Ppm
+	}
+	return 0
+}
+
+func (x *Measurement) GetTvocPpb() uint32 {
+	if x != nil {
+		return x.TvocPpb
+	}
+	return 0
+}
+
+func (x *Measurement) GetTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_measurement_proto protoreflect.FileDescriptor
+
+var file_measurement_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x05, 0x73, 0x67, 0x70, 0x33, 0x30, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x8b, 0x01, 0x0a, 0x0b,
+	0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x65, 0x72, 0x69, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65, 0x72,
+	0x69, 0x61, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x65, 0x63, 0x6f, 0x32, 0x5f, 0x70, 0x70, 0x6d, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x65, 0x63, 0x6f, 0x32, 0x50, 0x70, 0x6d, 0x12, 0x19,
+	0x0a, 0x08, 0x74, 0x76, 0x6f, 0x63, 0x5f, 0x70, 0x70, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x07, 0x74, 0x76, 0x6f, 0x63, 0x50, 0x70, 0x62, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x74, 0x61, 0x62, 0x6f, 0x6f, 0x2f, 0x73,
+	0x67, 0x70, 0x33, 0x30, 0x67, 0x6f, 0x2f, 0x73, 0x67, 0x70, 0x33, 0x30, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_measurement_proto_rawDescOnce sync.Once
+	file_measurement_proto_rawDescData = file_measurement_proto_rawDesc
+)
+
+func file_measurement_proto_rawDescGZIP() []byte {
+	file_measurement_proto_rawDescOnce.Do(func() {
+		file_measurement_proto_rawDescData = protoimpl.X.CompressGZIP(file_measurement_proto_rawDescData)
+	})
+	return file_measurement_proto_rawDescData
+}
+
+var file_measurement_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_measurement_proto_goTypes = []interface{}{
+	(*Measurement)(nil),         // 0: sgp30.Measurement
+	(*timestamp.Timestamp)(nil), // 1: google.protobuf.Timestamp
+}
+var file_measurement_proto_depIdxs = []int32{
+	1, // 0: sgp30.Measurement.time:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_measurement_proto_init() }
+func file_measurement_proto_init() {
+	if File_measurement_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_measurement_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Measurement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_measurement_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_measurement_proto_goTypes,
+		DependencyIndexes: file_measurement_proto_depIdxs,
+		MessageInfos:      file_measurement_proto_msgTypes,
+	}.Build()
+	File_measurement_proto = out.File
+	file_measurement_proto_rawDesc = nil
+	file_measurement_proto_goTypes = nil
+	file_measurement_proto_depIdxs = nil
+}
@@ -0,0 +1,38 @@
+package sgp30pb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ataboo/sgp30go/sensor"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestToProto(t *testing.T) {
+	timestamp := time.Date(2020, 1, 1, 10, 0, 0, 250000000, time.UTC)
+	m := sensor.Measurement{ECO2: 412, TVOC: 35, Timestamp: timestamp, SerialID: 0x010203040506}
+
+	p := ToProto(m)
+
+	if p.Serial != 0x010203040506 || p.Eco2Ppm != 412 || p.TvocPpb != 35 {
+		t.Error("unexpected fields", p)
+	}
+
+	if p.Time == nil || p.Time.Seconds != 1577872800 || p.Time.Nanos != 250000000 {
+		t.Error("unexpected timestamp", p.Time)
+	}
+
+	data, err := proto.Marshal(p)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	decoded := &Measurement{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !proto.Equal(decoded, p) || !decoded.GetTime().AsTime().Equal(timestamp) {
+		t.Error("unexpected round trip", decoded)
+	}
+}